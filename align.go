@@ -0,0 +1,122 @@
+package libretranslate
+
+import (
+	"context"
+	"strings"
+)
+
+// SentencePair is a sentence of a text and its translation.
+type SentencePair struct {
+	// Sentence of the original text
+	Source string
+	// Translation of the sentence
+	Target string
+}
+
+// AlignmentMismatch reports a sentence whose translation did not come back as
+// a single sentence.
+type AlignmentMismatch struct {
+	// Index of the pair of the sentence
+	Index int
+	// Number of sentences in the translation returned by the server: 0 if
+	// the server merged the sentence into another one, more than 1 if it
+	// split it
+	Sentences int
+	// Whether a sentence was moved between the translation of the pair and
+	// the translation of a neighboring pair to re-align them
+	Realigned bool
+}
+
+// AlignedResult represents the result of a sentence-aligned translation.
+type AlignedResult struct {
+	// Sentences of the text with their translations, in order
+	Pairs []SentencePair
+	// Sentences whose translation did not match one to one
+	Mismatches []AlignmentMismatch
+}
+
+// TranslateAligned translates a text sentence by sentence and returns each
+// sentence paired with its translation, so that the translation has as many
+// sentences as the text, such as for subtitles whose timing must stay
+// aligned. The text is split with SplitSentences and the sentences are
+// translated with a single batch request.
+//
+// Translating sentences on their own costs the server the context of the
+// others, so it may still merge or split them. When a translation comes back
+// empty and the translation of a neighboring sentence holds several
+// sentences, the extra sentence is moved to it (best effort). Every sentence
+// whose translation is not a single sentence is reported in Mismatches.
+func (c *Client) TranslateAligned(text, source, target string) (AlignedResult, error) {
+	return c.TranslateAlignedContext(context.Background(), text, source, target)
+}
+
+// TranslateAlignedContext is like TranslateAligned but uses the given context for the request.
+func (c *Client) TranslateAlignedContext(ctx context.Context, text, source, target string, opts ...CallOption) (AlignedResult, error) {
+	sentences := SplitSentences(text)
+	if len(sentences) == 0 {
+		return AlignedResult{}, nil
+	}
+
+	results, err := c.translateBatch(ctx, sentences, source, target, newCallOptions(opts))
+	if err != nil {
+		return AlignedResult{}, err
+	}
+
+	translated := make([][]string, len(results))
+	for i, result := range results {
+		translated[i] = SplitSentences(result.TranslatedText)
+	}
+
+	return alignSentences(sentences, translated), nil
+}
+
+// alignSentences pairs the sentences with the sentences of their
+// translations, moving a sentence to an empty translation from the
+// translation of a neighbor holding several ones.
+func alignSentences(sentences []string, translated [][]string) AlignedResult {
+	counts := make([]int, len(translated))
+	realigned := make([]bool, len(translated))
+
+	for i := range translated {
+		counts[i] = len(translated[i])
+	}
+
+	for i := range translated {
+		if len(translated[i]) > 0 {
+			continue
+		}
+
+		switch {
+		case i > 0 && len(translated[i-1]) > 1:
+			// The sentence was merged into the previous one.
+			last := len(translated[i-1]) - 1
+			translated[i] = translated[i-1][last:]
+			translated[i-1] = translated[i-1][:last]
+			realigned[i-1], realigned[i] = true, true
+		case i+1 < len(translated) && len(translated[i+1]) > 1:
+			// The sentence was merged into the next one.
+			translated[i] = translated[i+1][:1]
+			translated[i+1] = translated[i+1][1:]
+			realigned[i+1], realigned[i] = true, true
+		}
+	}
+
+	result := AlignedResult{Pairs: make([]SentencePair, len(sentences))}
+
+	for i, sentence := range sentences {
+		result.Pairs[i] = SentencePair{
+			Source: sentence,
+			Target: strings.Join(translated[i], " "),
+		}
+
+		if counts[i] != 1 {
+			result.Mismatches = append(result.Mismatches, AlignmentMismatch{
+				Index:     i,
+				Sentences: counts[i],
+				Realigned: realigned[i],
+			})
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,176 @@
+package libretranslate
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// apiKeyContextKey is the context key of the API key set by ContextWithAPIKey.
+type apiKeyContextKey struct{}
+
+// ContextWithAPIKey returns a copy of the context carrying an API key. Requests
+// made with the returned context use this key instead of the token of the
+// client, which suits middleware resolving credentials upstream.
+//
+// The key of a request is chosen in this order: the context key, the
+// WithAPIKey call option, then the token of the client (or the key of its
+// WithAPIKeyProvider).
+func ContextWithAPIKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// apiKeyTTL is how long a key returned by an API key provider is reused.
+const apiKeyTTL = time.Minute
+
+// WithAPIKeyProvider makes the client get its API key from the given function
+// instead of using the token given to the constructor, which keeps long-lived
+// clients working when the keys are rotated.
+//
+// The key is reused for one minute, or until the server rejects it as
+// invalid. If the provider fails, the request is not sent and the error is
+// returned.
+func WithAPIKeyProvider(provider func(context.Context) (string, error)) Option {
+	return func(c *Client) {
+		if provider == nil {
+			c.keys = nil
+			return
+		}
+
+		c.keys = &keyProvider{provide: provider}
+	}
+}
+
+// keyProvider caches the keys returned by an API key provider. A nil
+// *keyProvider provides no key.
+type keyProvider struct {
+	provide func(context.Context) (string, error)
+
+	mu      sync.Mutex
+	key     string
+	expires time.Time
+}
+
+// get returns the cached key, calling the provider if it has expired.
+func (p *keyProvider) get(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if time.Now().Before(p.expires) {
+		return p.key, nil
+	}
+
+	key, err := p.provide(ctx)
+	if err != nil {
+		return "", fmt.Errorf("api key provider error: %w", err)
+	}
+
+	p.key, p.expires = key, time.Now().Add(apiKeyTTL)
+
+	return key, nil
+}
+
+// invalidate makes the next request call the provider again.
+func (p *keyProvider) invalidate() {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.expires = time.Time{}
+}
+
+// clone returns a provider calling the same function, with an empty cache.
+func (p *keyProvider) clone() *keyProvider {
+	if p == nil {
+		return nil
+	}
+
+	return &keyProvider{provide: p.provide}
+}
+
+// apiKey returns the API key of a request made with the given context and
+// call option key.
+func (c *Client) apiKey(ctx context.Context, callKey string) (string, error) {
+	if key, ok := ctx.Value(apiKeyContextKey{}).(string); ok && key != "" {
+		return key, nil
+	}
+
+	if callKey != "" {
+		return callKey, nil
+	}
+
+	if c.keys != nil {
+		return c.keys.get(ctx)
+	}
+
+	return c.token, nil
+}
+
+// AuthPlacement is where the API key of a request is sent.
+type AuthPlacement int
+
+const (
+	// AuthBody sends the key with the other parameters, in the body of the
+	// request. This is the default for every method.
+	AuthBody AuthPlacement = iota
+	// AuthQuery sends the key in the query string of the url.
+	AuthQuery
+	// AuthHeader sends the key in an "Authorization: Bearer" header, which
+	// keeps it out of the urls and bodies logged along the way. The server,
+	// or a proxy in front of it, must accept the header.
+	AuthHeader
+)
+
+// WithAuthPlacement sets where the API key of the requests with the given
+// HTTP method is sent. The method is the one of the request as sent, which is
+// POST for every request with WithMethodOverride. The key is not sent in the
+// query or the header when it is empty.
+func WithAuthPlacement(method string, placement AuthPlacement) Option {
+	return func(c *Client) {
+		if c.authPlacements == nil {
+			c.authPlacements = make(map[string]AuthPlacement)
+		}
+
+		c.authPlacements[method] = placement
+	}
+}
+
+// placeAPIKey removes the API key from the parameters of a request sent with
+// the given method and adds it to the url or the header, according to its
+// placement. The given parameters are not modified.
+func (c *Client) placeAPIKey(method string, uri *url.URL, header http.Header, params url.Values) url.Values {
+	placement := c.authPlacements[method]
+	if placement == AuthBody {
+		return params
+	}
+
+	key := params.Get("api_key")
+
+	params = maps.Clone(params)
+	params.Del("api_key")
+
+	if key == "" {
+		return params
+	}
+
+	switch placement {
+	case AuthQuery:
+		query := uri.Query()
+		for name, values := range c.renameParams(url.Values{"api_key": {key}}) {
+			query[name] = values
+		}
+
+		uri.RawQuery = query.Encode()
+	case AuthHeader:
+		header.Set("Authorization", "Bearer "+key)
+	}
+
+	return params
+}
@@ -0,0 +1,189 @@
+package libretranslate
+
+import (
+	"net/url"
+	"sync"
+	"time"
+)
+
+// backendCooldown is how long a backend that failed is skipped.
+const backendCooldown = 30 * time.Second
+
+// Backend is an instance of the API used by the client.
+type Backend struct {
+	// Base url of the instance
+	URL string
+	// Share of the requests sent to the instance relative to the other
+	// backends (values lower than 1 count as 1)
+	Weight int
+}
+
+// WithBackends makes the client spread its requests across several instances
+// with a smooth weighted round-robin, instead of using the base url given to
+// the constructor.
+//
+// A backend that fails with a network error or an unavailable status (502,
+// 503, 504) is skipped for 30 seconds, unless all the backends are failing.
+// Combined with WithRetry, a failed request is retried on another backend.
+func WithBackends(backends ...Backend) Option {
+	return func(c *Client) {
+		if len(backends) == 0 {
+			c.backends = nil
+			return
+		}
+
+		pool := &backendPool{}
+		for _, backend := range backends {
+			backend.Weight = max(backend.Weight, 1)
+			state := &backendState{Backend: backend}
+			state.uri, state.uriErr = url.Parse(backend.URL)
+			pool.backends = append(pool.backends, state)
+		}
+
+		c.backends = pool
+	}
+}
+
+// CurrentBackend returns the base url the latest request was sent to.
+func (c *Client) CurrentBackend() string {
+	return c.baseURLFor(c.backends.last())
+}
+
+// baseURLFor returns the base url of the given backend, or the base url of the
+// client if there is none.
+func (c *Client) baseURLFor(backend *backendState) string {
+	if backend == nil {
+		return c.baseUrl
+	}
+
+	return backend.URL
+}
+
+// baseURIFor returns the parsed base url of the given backend, or the parsed
+// base url of the client if there is none.
+func (c *Client) baseURIFor(backend *backendState) (*url.URL, error) {
+	if backend == nil {
+		return c.baseURI, c.baseURIErr
+	}
+
+	return backend.uri, backend.uriErr
+}
+
+// backendState is a backend along with its selection and health state.
+type backendState struct {
+	Backend
+
+	uri           *url.URL
+	uriErr        error
+	currentWeight int
+	downUntil     time.Time
+}
+
+// backendPool selects the backend of each request. A nil *backendPool always
+// selects no backend.
+type backendPool struct {
+	mu       sync.Mutex
+	backends []*backendState
+	selected *backendState
+}
+
+// clone returns a pool with the same backends and a fresh selection and
+// health state.
+func (p *backendPool) clone() *backendPool {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pool := &backendPool{}
+	for _, backend := range p.backends {
+		pool.backends = append(pool.backends, &backendState{
+			Backend: backend.Backend,
+			uri:     backend.uri,
+			uriErr:  backend.uriErr,
+		})
+	}
+
+	return pool
+}
+
+// all returns the backends of the pool, or a single nil backend standing for
+// the base url of the client if there is no pool.
+func (p *backendPool) all() []*backendState {
+	if p == nil {
+		return []*backendState{nil}
+	}
+
+	return p.backends
+}
+
+// next selects the backend of the next request among the healthy ones, or
+// among all of them if none is healthy.
+func (p *backendPool) next() *backendState {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+
+	candidates := make([]*backendState, 0, len(p.backends))
+	for _, backend := range p.backends {
+		if now.After(backend.downUntil) {
+			candidates = append(candidates, backend)
+		}
+	}
+
+	if len(candidates) == 0 {
+		candidates = p.backends
+	}
+
+	var best *backendState
+
+	total := 0
+	for _, backend := range candidates {
+		backend.currentWeight += backend.Weight
+		total += backend.Weight
+
+		if best == nil || backend.currentWeight > best.currentWeight {
+			best = backend
+		}
+	}
+
+	best.currentWeight -= total
+	p.selected = best
+
+	return best
+}
+
+// report records the outcome of a request sent to the given backend.
+func (p *backendPool) report(backend *backendState, healthy bool) {
+	if p == nil || backend == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if healthy {
+		backend.downUntil = time.Time{}
+	} else {
+		backend.downUntil = time.Now().Add(backendCooldown)
+	}
+}
+
+// last returns the most recently selected backend.
+func (p *backendPool) last() *backendState {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.selected
+}
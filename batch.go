@@ -0,0 +1,434 @@
+package libretranslate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// batchTranslateResult represents the result for a translation query with several texts.
+type batchTranslateResult struct {
+	// Detected language information for each text (only for auto detect)
+	DetectedLanguage detectionLists `json:"detectedLanguage"`
+	// Alternative translations for each text (only if requested)
+	Alternatives [][]string `json:"alternatives"`
+	// Engine used for the translations (only if reported by the server)
+	Engine string `json:"engine"`
+	// Translated texts, in the same order as the queries
+	TranslatedText []string `json:"translatedText"`
+}
+
+// WithMaxBatchItems sets the maximum number of texts per batch request, for
+// instances limiting it. Larger batches are split into several requests, sent
+// one after the other, and the translations are returned in order.
+func WithMaxBatchItems(n int) Option {
+	return func(c *Client) {
+		c.maxBatchItems = n
+	}
+}
+
+// TranslateBatch makes a single request to translate several texts from one language to another,
+// or several requests with WithMaxBatchItems. The translations are returned in the same order as
+// the queries.
+//
+// If some of the requests made with WithMaxBatchItems fail, the translations of the other texts
+// are returned along with a *BatchError reporting the failed ones, whose translations are empty.
+func (c *Client) TranslateBatch(queries []string, source, target string) ([]string, error) {
+	return c.TranslateBatchContext(context.Background(), queries, source, target)
+}
+
+// TranslateBatchContext is like TranslateBatch but uses the given context for the request.
+func (c *Client) TranslateBatchContext(ctx context.Context, queries []string, source, target string, opts ...CallOption) ([]string, error) {
+	results, err := c.translateBatch(ctx, queries, source, target, newCallOptions(opts))
+
+	var batchErr *BatchError
+	if err != nil && !errors.As(err, &batchErr) {
+		return nil, err
+	}
+
+	translations := make([]string, len(results))
+	for i, result := range results {
+		translations[i] = result.TranslatedText
+	}
+
+	return translations, err
+}
+
+// TranslateBatchSparse is like TranslateBatchContext but does not send the
+// empty or whitespace-only texts, which are returned unchanged at their index.
+// No request is made if all the texts are empty.
+func (c *Client) TranslateBatchSparse(ctx context.Context, queries []string, source, target string, opts ...CallOption) ([]string, error) {
+	translations := make([]string, len(queries))
+
+	var (
+		indexes []int
+		texts   []string
+	)
+
+	for i, query := range queries {
+		if strings.TrimSpace(query) == "" {
+			translations[i] = query
+		} else {
+			indexes = append(indexes, i)
+			texts = append(texts, query)
+		}
+	}
+
+	if len(texts) == 0 {
+		return translations, nil
+	}
+
+	translated, err := c.TranslateBatchContext(ctx, texts, source, target, opts...)
+
+	var batchErr *BatchError
+	if err != nil && !errors.As(err, &batchErr) {
+		return nil, err
+	}
+
+	for j, i := range indexes {
+		translations[i] = translated[j]
+	}
+
+	if batchErr != nil {
+		// Report the failed items by their index in the queries.
+		failed := make(map[int]error, len(batchErr.Errors))
+		for j, itemErr := range batchErr.Errors {
+			failed[indexes[j]] = itemErr
+		}
+
+		return translations, &BatchError{Errors: failed, Total: len(queries)}
+	}
+
+	return translations, nil
+}
+
+// DetectedTranslation represents the translation of a text along with the
+// language detected for it.
+type DetectedTranslation struct {
+	// Translated text
+	Text string
+	// Detected language of the original text
+	Detected Detection
+}
+
+// TranslateBatchDetected makes a single request to translate several texts,
+// possibly in different languages, with automatic detection of the source
+// language, and returns the translations along with the language detected
+// for each text, in the same order as the queries.
+//
+// The best guess of the server is returned even if its confidence is low;
+// check Detection.Confidence to decide whether to trust it. Failed requests
+// are reported like TranslateBatch does.
+func (c *Client) TranslateBatchDetected(queries []string, target string) ([]DetectedTranslation, error) {
+	return c.TranslateBatchDetectedContext(context.Background(), queries, target)
+}
+
+// TranslateBatchDetectedContext is like TranslateBatchDetected but uses the given context for the request.
+func (c *Client) TranslateBatchDetectedContext(ctx context.Context, queries []string, target string, opts ...CallOption) ([]DetectedTranslation, error) {
+	results, err := c.translateBatch(ctx, queries, "auto", target, newCallOptions(opts))
+
+	var batchErr *BatchError
+	if err != nil && !errors.As(err, &batchErr) {
+		return nil, err
+	}
+
+	translations := make([]DetectedTranslation, len(results))
+	for i, result := range results {
+		translations[i] = DetectedTranslation{
+			Text:     result.TranslatedText,
+			Detected: result.DetectedLanguage,
+		}
+	}
+
+	return translations, err
+}
+
+// TranslateConcurrent translates several texts with one request per text,
+// running up to workers requests at a time (at least one). The translations
+// are returned in the same order as the queries.
+//
+// When the context is canceled or its deadline expires, the requests in flight
+// are aborted and the pending ones are not sent. The translations completed so
+// far are returned along with a *BatchError holding the error of every other
+// item, so errors.Is(err, context.DeadlineExceeded) reports a timeout.
+func (c *Client) TranslateConcurrent(ctx context.Context, queries []string, source, target string, workers int) ([]string, error) {
+	results := make([]string, len(queries))
+	errs := make([]error, len(queries))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < max(workers, 1); w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				result, err := c.translate(ctx, queries[i], source, target, callOptions{})
+				results[i], errs[i] = result.TranslatedText, err
+			}
+		}()
+	}
+
+feed:
+	for i := range queries {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			for j := i; j < len(queries); j++ {
+				errs[j] = ctx.Err()
+			}
+
+			break feed
+		}
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	return results, batchError(errs)
+}
+
+// batchError returns a *BatchError for the non-nil errors, or nil if there are none.
+func batchError(errs []error) error {
+	failed := make(map[int]error)
+	for i, err := range errs {
+		if err != nil {
+			failed[i] = err
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return &BatchError{Errors: failed, Total: len(errs)}
+}
+
+// translateBatch translates several texts and returns the full results.
+// Texts found in the response cache are not sent again.
+func (c *Client) translateBatch(ctx context.Context, queries []string, source, target string, opts callOptions) ([]TranslateResult, error) {
+	results := make([]TranslateResult, len(queries))
+
+	if c.isPassthrough(source, target) {
+		for i, query := range queries {
+			results[i] = passthroughResult(query, source)
+		}
+
+		return results, nil
+	}
+
+	var pending []int
+
+	for i, query := range queries {
+		if result, ok := c.responses.get(newResponseKey(ctx, query, source, target, opts)); ok {
+			results[i] = result
+		} else if result, ok := c.recall(ctx, query, source, target); ok {
+			results[i] = result
+		} else {
+			// Keep the warnings of a failed lookup for the translation.
+			results[i] = result
+			pending = append(pending, i)
+		}
+	}
+
+	switch len(pending) {
+	case 0:
+		return results, nil
+	case 1:
+		// A single text is answered with a single result instead of an array.
+		result, err := c.translate(ctx, queries[pending[0]], source, target, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		results[pending[0]] = result
+
+		return results, nil
+	}
+
+	protected := make([]protectedText, len(pending))
+	masked := make([]string, len(pending))
+
+	for j, i := range pending {
+		text, err := c.decodeInput(queries[i])
+		if err != nil {
+			return nil, err
+		}
+
+		protected[j] = c.protectPlaceholders(text)
+		masked[j] = protected[j].text
+	}
+
+	sent, err := c.sendTranslateBatch(ctx, masked, source, target, opts)
+	if errors.Is(err, ErrUnsupportedLanguagePair) && c.pivotLanguage != "" {
+		directErr := err
+		sent = make([]TranslateResult, len(masked))
+
+		for j := range masked {
+			if sent[j], err = c.translatePivoted(ctx, masked[j], source, target, opts, directErr); err != nil {
+				break
+			}
+		}
+	}
+
+	var failed map[int]error
+
+	if err != nil {
+		var batchErr *BatchError
+		if !errors.As(err, &batchErr) {
+			return nil, err
+		}
+
+		// Report the failed items by their index in the queries, and keep
+		// the translations of the others.
+		failed = make(map[int]error, len(batchErr.Errors))
+		for j, itemErr := range batchErr.Errors {
+			failed[pending[j]] = itemErr
+		}
+	}
+
+	for j, i := range pending {
+		if _, ok := failed[i]; ok {
+			continue
+		}
+
+		if err := c.checkEmpty(masked[j], sent[j].TranslatedText); err != nil {
+			return nil, fmt.Errorf("text %d: %w", i, err)
+		}
+	}
+
+	for j, i := range pending {
+		if _, ok := failed[i]; ok {
+			continue
+		}
+
+		sent[j].TranslatedText, sent[j].Warnings = protected[j].restore(sent[j].TranslatedText)
+		sent[j].TranslatedText = c.preserveSpace(queries[i], sent[j].TranslatedText)
+		sent[j].Source = queries[i]
+		sent[j].Warnings = append(sent[j].Warnings, results[i].Warnings...)
+		c.memorize(ctx, &sent[j], source, target)
+		c.responses.add(newResponseKey(ctx, queries[i], source, target, opts), sent[j])
+		results[i] = sent[j]
+	}
+
+	if len(failed) > 0 {
+		return results, &BatchError{Errors: failed, Total: len(queries)}
+	}
+
+	return results, nil
+}
+
+// sendTranslateBatch makes a request to translate several texts, sent again
+// while one of the translations matches the result pattern of the client.
+//
+// With WithMaxBatchItems, a larger batch is sent as several requests, one
+// after the other. If some of them fail, the translations of the others are
+// returned along with a *BatchError holding the error of each text of the
+// failed requests.
+func (c *Client) sendTranslateBatch(ctx context.Context, queries []string, source, target string, opts callOptions) ([]TranslateResult, error) {
+	if c.maxBatchItems <= 0 || len(queries) <= c.maxBatchItems {
+		return c.retryOnResult(ctx, func() ([]TranslateResult, error) {
+			return c.sendTranslateBatchOnce(ctx, queries, source, target, opts)
+		})
+	}
+
+	results := make([]TranslateResult, len(queries))
+	errs := make([]error, len(queries))
+
+	for start := 0; start < len(queries); start += c.maxBatchItems {
+		end := min(start+c.maxBatchItems, len(queries))
+
+		var (
+			sent []TranslateResult
+			err  = ctx.Err()
+		)
+
+		switch {
+		case err != nil:
+		case end-start == 1:
+			// A single text is answered with a single result instead of an array.
+			var result TranslateResult
+			result, err = c.sendTranslate(ctx, queries[start], source, target, opts)
+			sent = []TranslateResult{result}
+		default:
+			sent, err = c.sendTranslateBatch(ctx, queries[start:end], source, target, opts)
+		}
+
+		for i := start; i < end; i++ {
+			if err != nil {
+				errs[i] = err
+			} else {
+				results[i] = sent[i-start]
+			}
+		}
+	}
+
+	return results, batchError(errs)
+}
+
+// sendTranslateBatchOnce makes a request to translate several texts, without
+// retrying on the result pattern.
+func (c *Client) sendTranslateBatchOnce(ctx context.Context, queries []string, source, target string, opts callOptions) ([]TranslateResult, error) {
+	key, err := c.apiKey(ctx, opts.apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params["q"] = queries
+	params.Set("source", source)
+	params.Set("target", target)
+	params.Set("api_key", key)
+	opts.setParams(params)
+	c.adaptParams(ctx, params)
+
+	res, err := c.do(ctx, http.MethodPost, "/translate", params)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	batch := batchTranslateResult{DetectedLanguage: detectionLists{strict: c.strictDecoding}}
+	if err := c.decode(res, &batch); err != nil {
+		return nil, err
+	}
+
+	if len(batch.TranslatedText) != len(queries) {
+		return nil, fmt.Errorf(
+			"API error: expected %d translations and received %d",
+			len(queries),
+			len(batch.TranslatedText),
+		)
+	}
+
+	lists := make([][]Detection, len(batch.DetectedLanguage.lists))
+	for i, list := range batch.DetectedLanguage.lists {
+		lists[i] = list.list
+	}
+
+	c.normalizeConfidences(lists...)
+
+	results := make([]TranslateResult, len(queries))
+	for i, text := range batch.TranslatedText {
+		results[i].header = res.Header
+		results[i].TranslatedText = text
+		results[i].Engine = batch.Engine
+		if i < len(batch.DetectedLanguage.lists) {
+			results[i].DetectedLanguage, results[i].DetectionAlternatives = batch.DetectedLanguage.lists[i].split()
+		}
+		if i < len(batch.Alternatives) {
+			results[i].Alternatives = batch.Alternatives[i]
+		}
+
+		c.decodeEntities(queries[i], &results[i], opts)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,116 @@
+package libretranslate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// batchServer answers translation requests with the texts in upper case, and
+// fails the requests holding a text containing "fail".
+func batchServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var texts []string
+
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			var body struct {
+				Q any `json:"q"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decoding the request: %v", err)
+			}
+
+			switch q := body.Q.(type) {
+			case string:
+				texts = []string{q}
+			case []any:
+				for _, text := range q {
+					texts = append(texts, text.(string))
+				}
+			}
+		} else {
+			r.ParseForm()
+			texts = r.PostForm["q"]
+		}
+
+		for _, text := range texts {
+			if strings.Contains(text, "fail") {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"cannot translate"}`))
+
+				return
+			}
+		}
+
+		translated := make([]string, len(texts))
+		for i, text := range texts {
+			translated[i] = strings.ToUpper(text)
+		}
+
+		if len(translated) == 1 {
+			json.NewEncoder(w).Encode(map[string]any{"translatedText": translated[0]})
+		} else {
+			json.NewEncoder(w).Encode(map[string]any{"translatedText": translated})
+		}
+	}))
+}
+
+func TestTranslateBatchPartialResults(t *testing.T) {
+	srv := batchServer(t)
+	defer srv.Close()
+
+	c := NewClientWithBaseURL(srv.URL, "key", WithMaxBatchItems(2))
+
+	translations, err := c.TranslateBatch([]string{"a", "b", "c", "fail", "e"}, "en", "es")
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("got error %v, want a *BatchError", err)
+	}
+
+	failed := make([]int, 0, len(batchErr.Errors))
+	for index := range batchErr.Errors {
+		failed = append(failed, index)
+	}
+
+	slices.Sort(failed)
+
+	if !slices.Equal(failed, []int{2, 3}) {
+		t.Errorf("got failed items %v, want [2 3]", failed)
+	}
+
+	want := []string{"A", "B", "", "", "E"}
+	if !slices.Equal(translations, want) {
+		t.Errorf("got translations %q, want %q", translations, want)
+	}
+}
+
+func TestTranslateBatchSparsePartialResults(t *testing.T) {
+	srv := batchServer(t)
+	defer srv.Close()
+
+	c := NewClientWithBaseURL(srv.URL, "key", WithMaxBatchItems(2))
+
+	translations, err := c.TranslateBatchSparse(context.Background(), []string{"a", " ", "b", "fail", "c"}, "en", "es")
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("got error %v, want a *BatchError", err)
+	}
+
+	if _, ok := batchErr.Errors[4]; !ok || len(batchErr.Errors) != 2 {
+		t.Errorf("got failed items %v, want 3 and 4", batchErr.Errors)
+	}
+
+	want := []string{"A", " ", "B", "", ""}
+	if !slices.Equal(translations, want) {
+		t.Errorf("got translations %q, want %q", translations, want)
+	}
+}
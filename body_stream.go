@@ -0,0 +1,285 @@
+package libretranslate
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"unicode/utf8"
+)
+
+// streamChunkSize is the number of bytes of a parameter value escaped at
+// once when streaming a request body.
+const streamChunkSize = 16 << 10
+
+// WithStreamingThreshold makes the client stream the body of POST requests
+// whose parameters are larger than threshold bytes, such as the translation
+// of a whole document, instead of encoding it in memory. The body is encoded
+// while it is sent, so the peak memory of the request does not grow with the
+// size of the text.
+//
+// A streamed body is encoded twice, once to compute its Content-Length and
+// once to send it, unless it is compressed (see WithRequestCompression), in
+// which case it is sent with chunked transfer encoding. The body is encoded
+// again whenever it must be resent, so retries work as usual.
+func WithStreamingThreshold(threshold int) Option {
+	return func(c *Client) {
+		c.streamThreshold = threshold
+	}
+}
+
+// paramsSize returns the size of the keys and values of the parameters, an
+// estimate of the size of the request body.
+func paramsSize(params url.Values) int {
+	size := 0
+	for key, values := range params {
+		for _, value := range values {
+			size += len(key) + len(value)
+		}
+	}
+
+	return size
+}
+
+// newStreamingRequest creates a request whose body is encoded while it is
+// read. The encoding is the same as encodeBody.
+func (c *Client) newStreamingRequest(ctx context.Context, method, uri, contentType string, params url.Values) (*http.Request, error) {
+	write := func(w io.Writer) error {
+		return writeBody(w, contentType, params)
+	}
+
+	compressed := c.compressThreshold > 0 && paramsSize(params) > c.compressThreshold && !c.compressionRejected.Load()
+
+	length := int64(-1)
+	if compressed {
+		encode := write
+		write = func(w io.Writer) error {
+			zw := gzip.NewWriter(w)
+			if err := encode(zw); err != nil {
+				return err
+			}
+
+			return zw.Close()
+		}
+	} else {
+		var counter countingWriter
+		if err := write(&counter); err != nil {
+			return nil, fmt.Errorf("request body encoding error: %s", err)
+		}
+
+		length = counter.n
+	}
+
+	getBody := func() (io.ReadCloser, error) {
+		return &streamReader{write: write}, nil
+	}
+
+	body, _ := getBody()
+
+	req, err := http.NewRequestWithContext(ctx, method, uri, body)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request creation error: %s", err)
+	}
+
+	req.ContentLength = length
+	req.GetBody = getBody
+
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	return req, nil
+}
+
+// writeBody writes the parameters of a POST request according to the given
+// Content-Type, escaping the values in chunks.
+func writeBody(w io.Writer, contentType string, params url.Values) error {
+	bw := bufio.NewWriter(w)
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	var err error
+	if isJSONContentType(contentType) {
+		err = writeJSONBody(bw, keys, params)
+	} else {
+		writeFormBody(bw, keys, params)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// writeFormBody writes the parameters in URL-encoded form, like encodeForm.
+// Write errors are reported by the final flush.
+func writeFormBody(w *bufio.Writer, keys []string, params url.Values) {
+	first := true
+
+	for _, key := range keys {
+		for _, value := range params[key] {
+			if !first {
+				w.WriteByte('&')
+			}
+
+			first = false
+
+			w.WriteString(url.QueryEscape(key))
+			w.WriteByte('=')
+
+			for rest := value; rest != ""; {
+				n := chunkSize(rest)
+				w.WriteString(url.QueryEscape(rest[:n]))
+				rest = rest[n:]
+			}
+		}
+	}
+}
+
+// writeJSONBody writes the parameters as a JSON object, like encodeBody: a
+// single value is written as a string, and several as an array of strings.
+func writeJSONBody(w *bufio.Writer, keys []string, params url.Values) error {
+	w.WriteByte('{')
+
+	for i, key := range keys {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+
+		if err := writeJSONString(w, key); err != nil {
+			return err
+		}
+
+		w.WriteByte(':')
+
+		values := params[key]
+		if len(values) == 1 {
+			if err := writeJSONString(w, values[0]); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if values == nil {
+			w.WriteString("null")
+			continue
+		}
+
+		w.WriteByte('[')
+
+		for j, value := range values {
+			if j > 0 {
+				w.WriteByte(',')
+			}
+
+			if err := writeJSONString(w, value); err != nil {
+				return err
+			}
+		}
+
+		w.WriteByte(']')
+	}
+
+	w.WriteByte('}')
+
+	return nil
+}
+
+// writeJSONString writes a string as a JSON string, escaped like
+// json.Marshal. Runes are escaped on their own, so the string is escaped in
+// chunks split on rune boundaries.
+func writeJSONString(w *bufio.Writer, s string) error {
+	w.WriteByte('"')
+
+	for s != "" {
+		n := chunkSize(s)
+
+		escaped, err := json.Marshal(s[:n])
+		if err != nil {
+			return err
+		}
+
+		w.Write(escaped[1 : len(escaped)-1])
+		s = s[n:]
+	}
+
+	w.WriteByte('"')
+
+	return nil
+}
+
+// chunkSize returns the size of the next piece of a string to escape, about
+// streamChunkSize bytes, split on a rune boundary.
+func chunkSize(s string) int {
+	n := min(streamChunkSize, len(s))
+	for n < len(s) && !utf8.RuneStart(s[n]) {
+		n++
+	}
+
+	return n
+}
+
+// countingWriter counts the bytes written to it and discards them.
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+
+	return len(p), nil
+}
+
+// streamReader is a request body written by a function through a pipe. The
+// function only starts on the first read, so a body that is never read does
+// not leave a goroutine behind, and it stops when the body is closed.
+type streamReader struct {
+	write func(io.Writer) error
+
+	once   sync.Once
+	reader *io.PipeReader
+}
+
+func (r *streamReader) Read(p []byte) (int, error) {
+	r.once.Do(r.start)
+
+	if r.reader == nil {
+		return 0, io.ErrClosedPipe
+	}
+
+	return r.reader.Read(p)
+}
+
+// Close stops the writing function, if it was started.
+func (r *streamReader) Close() error {
+	r.once.Do(func() {})
+
+	if r.reader == nil {
+		return nil
+	}
+
+	return r.reader.Close()
+}
+
+// start runs the writing function in a goroutine.
+func (r *streamReader) start() {
+	reader, writer := io.Pipe()
+	r.reader = reader
+
+	go func() {
+		writer.CloseWithError(r.write(writer))
+	}()
+}
@@ -0,0 +1,341 @@
+package libretranslate
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// cache holds the responses of the endpoints that rarely change during the
+// lifetime of a client.
+type cache struct {
+	mu        sync.Mutex
+	languages []Language
+	settings  *Settings
+	version   *string
+	// versionFailure is the time of the last failed fetch of the version
+	versionFailure time.Time
+	// validators holds the ETag and Last-Modified headers of the response
+	// the languages come from, if any
+	validators http.Header
+}
+
+// languages returns the cached languages, fetching them if they are not cached yet.
+func (c *Client) languages(ctx context.Context) ([]Language, error) {
+	if languages, ok := c.cache.getLanguages(); ok {
+		return languages, nil
+	}
+
+	return c.GetLanguagesContext(ctx)
+}
+
+// settings returns the cached settings, fetching them if they are not cached yet.
+func (c *Client) settings(ctx context.Context) (Settings, error) {
+	if settings, ok := c.cache.getSettings(); ok {
+		return settings, nil
+	}
+
+	return c.GetSettingsContext(ctx)
+}
+
+// getLanguages returns a copy of the cached languages, if any.
+func (c *cache) getLanguages() ([]Language, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.languages == nil {
+		return nil, false
+	}
+
+	return cloneLanguages(c.languages), true
+}
+
+// setLanguages stores a copy of the given languages, along with the
+// validators of the response they come from (nil if none).
+func (c *cache) setLanguages(languages []Language, validators http.Header) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.languages = cloneLanguages(languages)
+	c.validators = validators
+}
+
+// languagesConditions returns the headers making a request for the languages
+// conditional on the cached ones being outdated, or nil if there are no
+// cached languages or they came without validators.
+func (c *cache) languagesConditions() http.Header {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.languages == nil || c.validators == nil {
+		return nil
+	}
+
+	conditions := make(http.Header)
+	if etag := c.validators.Get("ETag"); etag != "" {
+		conditions.Set("If-None-Match", etag)
+	}
+
+	if modified := c.validators.Get("Last-Modified"); modified != "" {
+		conditions.Set("If-Modified-Since", modified)
+	}
+
+	return conditions
+}
+
+// responseValidators returns the ETag and Last-Modified headers of a
+// response, or nil if it has none.
+func responseValidators(header http.Header) http.Header {
+	var validators http.Header
+
+	for _, name := range []string{"ETag", "Last-Modified"} {
+		if value := header.Get(name); value != "" {
+			if validators == nil {
+				validators = make(http.Header)
+			}
+
+			validators.Set(name, value)
+		}
+	}
+
+	return validators
+}
+
+// cloneLanguages returns a deep copy of the given languages.
+func cloneLanguages(languages []Language) []Language {
+	languages = slices.Clone(languages)
+	for i := range languages {
+		languages[i].Targets = slices.Clone(languages[i].Targets)
+	}
+
+	return languages
+}
+
+// getSettings returns a copy of the cached settings, if any.
+func (c *cache) getSettings() (Settings, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.settings == nil {
+		return Settings{}, false
+	}
+
+	return *c.settings, true
+}
+
+// setSettings stores a copy of the given settings.
+func (c *cache) setSettings(settings Settings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.settings = &settings
+}
+
+// getVersion returns the cached server version, if any.
+func (c *cache) getVersion() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.version == nil {
+		return "", false
+	}
+
+	return *c.version, true
+}
+
+// setVersion stores the server version.
+func (c *cache) setVersion(version string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version = &version
+}
+
+// setVersionFailure records a failed fetch of the server version.
+func (c *cache) setVersionFailure(at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.versionFailure = at
+}
+
+// versionFailedSince reports whether a fetch of the server version failed
+// after the given time.
+func (c *cache) versionFailedSince(t time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.versionFailure.After(t)
+}
+
+// WithResponseCache makes the client keep up to size translation results in
+// memory and return them for identical requests (same text, languages, format,
+// number of alternatives, engine and API key set with WithAPIKey or
+// ContextWithAPIKey) instead of calling the API again. Results
+// older than ttl are discarded; a zero ttl keeps them until they are evicted.
+//
+// Only translations are cached. Once the cache is full, the least recently
+// used result is evicted.
+func WithResponseCache(size int, ttl time.Duration) Option {
+	return func(c *Client) {
+		if size <= 0 {
+			c.responses = nil
+			return
+		}
+
+		c.responses = &responseCache{
+			size:  size,
+			ttl:   ttl,
+			items: make(map[responseKey]*list.Element, size),
+			order: list.New(),
+		}
+	}
+}
+
+// responseKey identifies a translation request in the response cache.
+type responseKey struct {
+	query        string
+	source       string
+	target       string
+	format       string
+	alternatives int
+	engine       string
+	// apiKey holds the key overriding the one of the client, if any, since
+	// the instance may answer differently (or not at all) for another key
+	apiKey string
+}
+
+// newResponseKey returns the cache key of a translation request made with the
+// given context.
+func newResponseKey(ctx context.Context, query, source, target string, opts callOptions) responseKey {
+	apiKey := opts.apiKey
+	if key, ok := ctx.Value(apiKeyContextKey{}).(string); ok && key != "" {
+		apiKey = key
+	}
+
+	return responseKey{
+		query:        query,
+		source:       source,
+		target:       target,
+		format:       opts.format,
+		alternatives: opts.alternatives,
+		engine:       opts.engine,
+		apiKey:       apiKey,
+	}
+}
+
+// responseEntry is an element of the response cache.
+type responseEntry struct {
+	key     responseKey
+	result  TranslateResult
+	expires time.Time
+}
+
+// responseCache is a concurrency-safe LRU cache of translation results.
+// A nil *responseCache is a disabled cache.
+type responseCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	items map[responseKey]*list.Element
+	order *list.List
+}
+
+// clone returns an empty cache with the same size and ttl.
+func (rc *responseCache) clone() *responseCache {
+	if rc == nil {
+		return nil
+	}
+
+	return &responseCache{
+		size:  rc.size,
+		ttl:   rc.ttl,
+		items: make(map[responseKey]*list.Element, rc.size),
+		order: list.New(),
+	}
+}
+
+// clear removes all the results.
+func (rc *responseCache) clear() {
+	if rc == nil {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	clear(rc.items)
+	rc.order.Init()
+}
+
+// get returns a copy of the cached result for the given key, if any.
+func (rc *responseCache) get(key responseKey) (TranslateResult, bool) {
+	if rc == nil {
+		return TranslateResult{}, false
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	elem, ok := rc.items[key]
+	if !ok {
+		return TranslateResult{}, false
+	}
+
+	entry := elem.Value.(*responseEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		rc.order.Remove(elem)
+		delete(rc.items, key)
+
+		return TranslateResult{}, false
+	}
+
+	rc.order.MoveToFront(elem)
+
+	return cloneResult(entry.result), true
+}
+
+// add stores a copy of the result for the given key, evicting the least
+// recently used result if the cache is full.
+func (rc *responseCache) add(key responseKey, result TranslateResult) {
+	if rc == nil {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry := &responseEntry{key: key, result: cloneResult(result)}
+	if rc.ttl > 0 {
+		entry.expires = time.Now().Add(rc.ttl)
+	}
+
+	if elem, ok := rc.items[key]; ok {
+		elem.Value = entry
+		rc.order.MoveToFront(elem)
+
+		return
+	}
+
+	rc.items[key] = rc.order.PushFront(entry)
+
+	if rc.order.Len() > rc.size {
+		oldest := rc.order.Back()
+		rc.order.Remove(oldest)
+		delete(rc.items, oldest.Value.(*responseEntry).key)
+	}
+}
+
+// cloneResult returns a copy of a result that does not share its slices. The
+// response headers are dropped, since a cached result is not a response.
+func cloneResult(result TranslateResult) TranslateResult {
+	result.Alternatives = slices.Clone(result.Alternatives)
+	result.DetectionAlternatives = slices.Clone(result.DetectionAlternatives)
+	result.Warnings = slices.Clone(result.Warnings)
+	result.header = nil
+
+	return result
+}
@@ -0,0 +1,52 @@
+package libretranslate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestResponseCacheAPIKey(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		r.ParseForm()
+		w.Write([]byte(`{"translatedText":"hola ` + r.PostForm.Get("api_key") + `"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClientWithBaseURL(srv.URL, "default", WithResponseCache(10, 0))
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		opts      []CallOption
+		want      string
+		wantCalls int32
+	}{
+		{"client key", context.Background(), nil, "hola default", 1},
+		{"call key", context.Background(), []CallOption{WithAPIKey("a")}, "hola a", 2},
+		{"context key", ContextWithAPIKey(context.Background(), "b"), nil, "hola b", 3},
+		{"cached client key", context.Background(), nil, "hola default", 3},
+		{"cached call key", context.Background(), []CallOption{WithAPIKey("a")}, "hola a", 3},
+		{"cached context key", ContextWithAPIKey(context.Background(), "b"), []CallOption{WithAPIKey("a")}, "hola b", 3},
+	}
+
+	for _, tt := range tests {
+		result, err := c.TranslateDetailed(tt.ctx, "hello", "en", "es", tt.opts...)
+		if err != nil {
+			t.Fatalf("%s: TranslateDetailed: %v", tt.name, err)
+		}
+
+		if result.TranslatedText != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, result.TranslatedText, tt.want)
+		}
+
+		if got := calls.Load(); got != tt.wantCalls {
+			t.Errorf("%s: got %d requests, want %d", tt.name, got, tt.wantCalls)
+		}
+	}
+}
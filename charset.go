@@ -0,0 +1,45 @@
+package libretranslate
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+)
+
+// ErrInvalidUTF8 is returned when a text to send is not valid UTF-8 and the
+// client has no input charset.
+var ErrInvalidUTF8 = errors.New("text is not valid UTF-8")
+
+// WithInputCharset makes the client transcode the texts to detect and
+// translate from the given encoding (for instance charmap.ISO8859_1 from
+// golang.org/x/text/encoding/charmap) before sending them, for legacy data.
+// The API only accepts UTF-8, so the translations are always UTF-8.
+//
+// Without an input charset, texts that are not valid UTF-8 are rejected with
+// ErrInvalidUTF8 instead of being mangled by the server.
+func WithInputCharset(enc encoding.Encoding) Option {
+	return func(c *Client) {
+		c.inputCharset = enc
+	}
+}
+
+// decodeInput returns a text to send as UTF-8, decoding it from the input
+// charset of the client if there is one.
+func (c *Client) decodeInput(text string) (string, error) {
+	if c.inputCharset == nil {
+		if !utf8.ValidString(text) {
+			return "", ErrInvalidUTF8
+		}
+
+		return text, nil
+	}
+
+	decoded, err := c.inputCharset.NewDecoder().String(text)
+	if err != nil {
+		return "", fmt.Errorf("charset decoding error: %w", err)
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,112 @@
+package libretranslate
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// noisePattern matches the parts of a text that carry no language
+// information: urls, email addresses, mentions and hashtags.
+var noisePattern = regexp.MustCompile(`https?://\S+|www\.\S+|\S+@\S+\.\w+|[@#]\w+`)
+
+// CleanForDetection removes from a text what tends to confuse language
+// detection: urls, email addresses, mentions, hashtags, emoji and other
+// symbols, and digits. It is the default cleaning function of
+// WithCleanInputForDetection.
+func CleanForDetection(text string) string {
+	text = noisePattern.ReplaceAllString(text, " ")
+
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) || unicode.Is(unicode.So, r) || unicode.Is(unicode.Sk, r) {
+			return ' '
+		}
+
+		if unicode.Is(unicode.Cf, r) || unicode.Is(unicode.Mn, r) && r >= 0xFE00 {
+			// Drop joiners and variation selectors left by emoji.
+			return -1
+		}
+
+		return r
+	}, text)
+
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// WithCleanInputForDetection makes the client check the detected language of
+// translations requested with the "auto" source. When the confidence is below
+// minConfidence (from 0 to 1, like Detection.Confidence), the language of the
+// text is detected again after cleaning it with the given function
+// (CleanForDetection if nil), and if the cleaned text is detected as another
+// language with a higher confidence, the original text is translated again
+// from that language.
+//
+// The result then holds the second detection in DetectedLanguage and the first
+// one in InitialDetection. If the second detection fails, the translation is
+// returned as it is and a warning is logged (see WithLogger). Batch
+// translations are not checked.
+func WithCleanInputForDetection(minConfidence float64, clean func(string) string) Option {
+	return func(c *Client) {
+		if clean == nil {
+			clean = CleanForDetection
+		}
+
+		c.detectionCleaner = clean
+		c.minDetectionConfidence = minConfidence
+	}
+}
+
+// redetect detects the language of a cleaned text if the detection of its
+// translation has a low confidence, and translates the (protected) text again
+// from the language detected if it differs. If the detection fails, the
+// translation is returned as it is.
+func (c *Client) redetect(ctx context.Context, query, text, target string, result TranslateResult, opts callOptions) (TranslateResult, error) {
+	initial := result.DetectedLanguage
+	if c.detectionCleaner == nil || initial.Confidence >= c.minDetectionConfidence {
+		return result, nil
+	}
+
+	cleaned := c.detectionCleaner(query)
+	if cleaned == query || strings.TrimSpace(cleaned) == "" {
+		return result, nil
+	}
+
+	detections, err := c.DetectContext(ctx, cleaned)
+	if err != nil {
+		// The translation is still valid, only the second opinion is missing.
+		c.log(ctx, slog.LevelWarn, "libretranslate: detection of the cleaned text failed", "error", err)
+
+		return result, nil
+	}
+
+	detected, ok := topDetection(detections)
+	if !ok || detected.Confidence <= initial.Confidence {
+		return result, nil
+	}
+
+	if detected.Language != initial.Language {
+		if result, err = c.sendTranslate(ctx, text, detected.Language, target, opts); err != nil {
+			return TranslateResult{}, err
+		}
+	}
+
+	result.DetectedLanguage = detected
+	result.DetectionAlternatives = nil
+	result.InitialDetection = &initial
+
+	for _, detection := range detections {
+		if detection != detected {
+			result.DetectionAlternatives = append(result.DetectionAlternatives, detection)
+		}
+	}
+
+	slices.SortStableFunc(result.DetectionAlternatives, func(a, b Detection) int {
+		return cmp.Compare(b.Confidence, a.Confidence)
+	})
+
+	return result, nil
+}
@@ -0,0 +1,45 @@
+package libretranslate
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedetectFailureKeepsTranslation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/detect" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"detection failed"}`))
+
+			return
+		}
+
+		w.Write([]byte(`{"translatedText":"hola","detectedLanguage":{"confidence":20,"language":"en"}}`))
+	}))
+	defer srv.Close()
+
+	var logs bytes.Buffer
+
+	c := NewClientWithBaseURL(srv.URL, "key",
+		WithCleanInputForDetection(0.5, nil),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+
+	result, err := c.TranslateDetailed(context.Background(), "hello https://example.com", "auto", "es")
+	if err != nil {
+		t.Fatalf("TranslateDetailed: %v", err)
+	}
+
+	if result.TranslatedText != "hola" || result.DetectedLanguage.Language != "en" || result.InitialDetection != nil {
+		t.Errorf("got %+v, want the first translation", result)
+	}
+
+	if !strings.Contains(logs.String(), "level=WARN") || !strings.Contains(logs.String(), "detection failed") {
+		t.Errorf("got logs %q, want a warning about the failed detection", logs.String())
+	}
+}
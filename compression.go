@@ -0,0 +1,40 @@
+package libretranslate
+
+import (
+	"bytes"
+	"compress/gzip"
+)
+
+// WithRequestCompression makes the client gzip the body of POST requests
+// larger than threshold bytes and send them with "Content-Encoding: gzip",
+// which reduces the upload size of long texts.
+//
+// The instance (or a proxy in front of it) must accept compressed bodies. If
+// it answers with 415 Unsupported Media Type, the request is sent again
+// uncompressed and the client stops compressing requests.
+func WithRequestCompression(threshold int) Option {
+	return func(c *Client) {
+		c.compressThreshold = threshold
+	}
+}
+
+// compressBody returns the gzipped body and true if the body should be
+// compressed, or the original body and false otherwise.
+func (c *Client) compressBody(body []byte) ([]byte, bool) {
+	if c.compressThreshold <= 0 || len(body) <= c.compressThreshold || c.compressionRejected.Load() {
+		return body, false
+	}
+
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return body, false
+	}
+
+	if err := zw.Close(); err != nil {
+		return body, false
+	}
+
+	return buf.Bytes(), true
+}
@@ -0,0 +1,181 @@
+package libretranslate
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Config holds the common settings of a Client, for applications loading
+// them from a configuration file or the environment. It is an alternative to
+// the options of NewClientWithBaseURL; see NewClientFromConfig.
+//
+// The zero value of a field leaves the default of the client. The durations
+// are time.Duration values, which most YAML and environment decoders parse
+// from strings such as "30s" (encoding/json expects nanoseconds).
+type Config struct {
+	// Base url of the API (DefaultBaseURL if empty)
+	BaseURL string `json:"baseURL" yaml:"baseURL"`
+	// Path the API is mounted at on the server (see WithAPIBasePath)
+	APIBasePath string `json:"apiBasePath" yaml:"apiBasePath"`
+	// API key sent with the requests
+	Token string `json:"token" yaml:"token"`
+	// Content-Type of the POST requests (see WithContentType)
+	ContentType string `json:"contentType" yaml:"contentType"`
+	// User-Agent header of the requests (see WithUserAgent)
+	UserAgent string `json:"userAgent" yaml:"userAgent"`
+
+	// Time limit of each request (see WithTimeout)
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// Idle time after which connections are closed (see WithIdleConnTimeout)
+	IdleConnTimeout time.Duration `json:"idleConnTimeout" yaml:"idleConnTimeout"`
+	// HTTP client sending the requests (see WithHTTPClient)
+	HTTPClient *http.Client `json:"-" yaml:"-"`
+	// Whether Timeout leaves the Timeout of HTTPClient as it is (see
+	// WithKeepHTTPClientTimeout)
+	KeepHTTPClientTimeout bool `json:"keepHTTPClientTimeout" yaml:"keepHTTPClientTimeout"`
+
+	// Maximum number of attempts of a request, including the first one (no
+	// retries if 0 or 1, see WithRetry)
+	RetryMaxAttempts int `json:"retryMaxAttempts" yaml:"retryMaxAttempts"`
+	// Delay before the first retry, growing exponentially
+	RetryBaseDelay time.Duration `json:"retryBaseDelay" yaml:"retryBaseDelay"`
+	// Maximum delay between two attempts (see WithRetryMaxDelay)
+	RetryMaxDelay time.Duration `json:"retryMaxDelay" yaml:"retryMaxDelay"`
+	// Maximum total time of a request and its retries (see WithRetryBudget)
+	RetryBudget time.Duration `json:"retryBudget" yaml:"retryBudget"`
+
+	// Maximum number of texts sent in a single batch request (see
+	// WithMaxBatchItems)
+	MaxBatchItems int `json:"maxBatchItems" yaml:"maxBatchItems"`
+	// Logger of the client (see WithLogger)
+	Logger *slog.Logger `json:"-" yaml:"-"`
+	// Options applied after the settings above, for the settings Config
+	// does not hold
+	Options []Option `json:"-" yaml:"-"`
+}
+
+// NewClientFromConfig returns a new API client configured from cfg, or an
+// error if the settings are invalid or conflict with each other.
+//
+// Since the default instance requires an API key, an error is returned if the
+// base url is not set and the token is missing, like NewClientFromEnv.
+func NewClientFromConfig(cfg Config) (*Client, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("config error: %w", err)
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	var opts []Option
+
+	if cfg.APIBasePath != "" {
+		opts = append(opts, WithAPIBasePath(cfg.APIBasePath))
+	}
+
+	if cfg.ContentType != "" {
+		opts = append(opts, WithContentType(cfg.ContentType))
+	}
+
+	if cfg.UserAgent != "" {
+		opts = append(opts, WithUserAgent(cfg.UserAgent))
+	}
+
+	if cfg.HTTPClient != nil {
+		opts = append(opts, WithHTTPClient(cfg.HTTPClient))
+	}
+
+	if cfg.Timeout > 0 {
+		opts = append(opts, WithTimeout(cfg.Timeout))
+	}
+
+	if cfg.KeepHTTPClientTimeout {
+		opts = append(opts, WithKeepHTTPClientTimeout())
+	}
+
+	if cfg.IdleConnTimeout > 0 {
+		opts = append(opts, WithIdleConnTimeout(cfg.IdleConnTimeout))
+	}
+
+	if cfg.RetryMaxAttempts > 1 {
+		opts = append(opts, WithRetry(cfg.RetryMaxAttempts, cfg.RetryBaseDelay))
+	}
+
+	if cfg.RetryMaxDelay > 0 {
+		opts = append(opts, WithRetryMaxDelay(cfg.RetryMaxDelay))
+	}
+
+	if cfg.RetryBudget > 0 {
+		opts = append(opts, WithRetryBudget(cfg.RetryBudget))
+	}
+
+	if cfg.MaxBatchItems > 0 {
+		opts = append(opts, WithMaxBatchItems(cfg.MaxBatchItems))
+	}
+
+	if cfg.Logger != nil {
+		opts = append(opts, WithLogger(cfg.Logger))
+	}
+
+	opts = append(opts, cfg.Options...)
+
+	return NewClientWithBaseURL(baseURL, cfg.Token, opts...), nil
+}
+
+// validate reports the first invalid or conflicting setting of the config.
+func (cfg Config) validate() error {
+	if cfg.BaseURL == "" {
+		if cfg.Token == "" {
+			return errors.New("token is required when the base url is not set")
+		}
+	} else {
+		uri, err := url.Parse(cfg.BaseURL)
+		if err != nil {
+			return fmt.Errorf("invalid base url: %w", err)
+		}
+
+		if uri.Scheme != "http" && uri.Scheme != "https" || uri.Host == "" {
+			return fmt.Errorf("invalid base url %q: an absolute http or https url is required", cfg.BaseURL)
+		}
+	}
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"timeout", cfg.Timeout},
+		{"idle connection timeout", cfg.IdleConnTimeout},
+		{"retry base delay", cfg.RetryBaseDelay},
+		{"retry max delay", cfg.RetryMaxDelay},
+		{"retry budget", cfg.RetryBudget},
+	}
+
+	for _, d := range durations {
+		if d.value < 0 {
+			return fmt.Errorf("negative %s %s", d.name, d.value)
+		}
+	}
+
+	switch {
+	case cfg.RetryMaxAttempts < 0:
+		return fmt.Errorf("negative retry max attempts %d", cfg.RetryMaxAttempts)
+	case cfg.MaxBatchItems < 0:
+		return fmt.Errorf("negative max batch items %d", cfg.MaxBatchItems)
+	case cfg.RetryMaxAttempts <= 1 && (cfg.RetryBaseDelay > 0 || cfg.RetryMaxDelay > 0 || cfg.RetryBudget > 0):
+		return fmt.Errorf("retry delays are set but retries are disabled (retry max attempts %d)", cfg.RetryMaxAttempts)
+	case cfg.RetryMaxDelay > 0 && cfg.RetryMaxDelay < cfg.RetryBaseDelay:
+		return fmt.Errorf("retry max delay %s is shorter than the retry base delay %s", cfg.RetryMaxDelay, cfg.RetryBaseDelay)
+	case cfg.KeepHTTPClientTimeout && cfg.HTTPClient == nil:
+		return errors.New("keeping the HTTP client timeout requires an HTTP client")
+	case cfg.KeepHTTPClientTimeout && cfg.Timeout > 0:
+		return fmt.Errorf("timeout %s is set but the HTTP client timeout is kept", cfg.Timeout)
+	}
+
+	return nil
+}
@@ -0,0 +1,361 @@
+package libretranslate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// LanguageInfo describes a language consistently, whether it comes from a
+// detection, the detected language of a translation or the languages list.
+type LanguageInfo struct {
+	// Language code
+	Code string
+	// Human-readable language name (in English), empty if unknown
+	Name string
+	// Confidence value (zero if the language was not detected)
+	Confidence float64
+}
+
+// DetectionNamed represents a detected language along with its name.
+type DetectionNamed = LanguageInfo
+
+// DescribeLanguages returns the LanguageInfo of the given detections, such as
+// the result of Detect or TranslateResult.DetectedLanguage, with the names
+// taken from the cached list of languages (fetched on first use). Languages
+// missing from the list are returned with an empty name.
+func (c *Client) DescribeLanguages(ctx context.Context, detections ...Detection) ([]LanguageInfo, error) {
+	languages, err := c.languages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(languages))
+	for _, language := range languages {
+		names[language.Code] = language.Name
+	}
+
+	result := make([]LanguageInfo, len(detections))
+	for i, detection := range detections {
+		result[i] = LanguageInfo{
+			Code:       detection.Language,
+			Name:       names[detection.Language],
+			Confidence: detection.Confidence,
+		}
+	}
+
+	return result, nil
+}
+
+// DetectNamed is like Detect but also returns the name of each detected
+// language, as described by DescribeLanguages.
+func (c *Client) DetectNamed(q string) ([]DetectionNamed, error) {
+	return c.DetectNamedContext(context.Background(), q)
+}
+
+// DetectNamedContext is like DetectNamed but uses the given context for the requests.
+func (c *Client) DetectNamedContext(ctx context.Context, q string) ([]DetectionNamed, error) {
+	detections, err := c.DetectContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.DescribeLanguages(ctx, detections...)
+}
+
+// defaultDominantSampler is the Splitter used by DetectDominant unless the
+// client was created with WithDominantSampler.
+var defaultDominantSampler = SampleEvenly(SplitParagraphs, 5)
+
+// DetectBatch detects the languages of several texts, one request at a time,
+// and returns the detections of each text in the same order.
+//
+// A failed detection does not stop the batch: its error is reported in a
+// *BatchError returned along with the results. When the context is canceled,
+// the remaining texts are not sent and fail with the context error.
+func (c *Client) DetectBatch(ctx context.Context, queries []string) ([][]Detection, error) {
+	results := make([][]Detection, len(queries))
+	errs := make([]error, len(queries))
+
+	for i, query := range queries {
+		if err := ctx.Err(); err != nil {
+			for j := i; j < len(queries); j++ {
+				errs[j] = err
+			}
+
+			break
+		}
+
+		results[i], errs[i] = c.DetectContext(ctx, query)
+	}
+
+	return results, batchError(errs)
+}
+
+// DetectBatchNamed is like DetectBatch but also returns the name of each
+// detected language, as described by DescribeLanguages. The languages are
+// fetched once, then read from the client cache.
+func (c *Client) DetectBatchNamed(ctx context.Context, queries []string) ([][]DetectionNamed, error) {
+	// Fail before sending the detections if the names are not available.
+	if _, err := c.languages(ctx); err != nil {
+		return nil, err
+	}
+
+	detections, batchErr := c.DetectBatch(ctx, queries)
+
+	results := make([][]DetectionNamed, len(detections))
+	for i := range detections {
+		named, err := c.DescribeLanguages(ctx, detections[i]...)
+		if err != nil {
+			return nil, err
+		}
+
+		results[i] = named
+	}
+
+	return results, batchErr
+}
+
+// WithDominantSampler sets the Splitter choosing the parts of a document that
+// DetectDominant sends for detection. The default samples up to 5 paragraphs
+// evenly spaced over the document.
+func WithDominantSampler(sampler Splitter) Option {
+	return func(c *Client) {
+		c.dominantSampler = sampler
+	}
+}
+
+// DetectDominant detects the dominant language of a long document, which a
+// single detection on the whole text can get wrong.
+//
+// Samples of the document (paragraphs by default, see WithDominantSampler) are
+// detected separately. The language detected in most samples wins, with ties
+// broken by the total confidence. The returned confidence is the average over
+// the samples detected in that language.
+func (c *Client) DetectDominant(text string) (Detection, error) {
+	return c.DetectDominantContext(context.Background(), text)
+}
+
+// DetectDominantContext is like DetectDominant but uses the given context for the requests.
+func (c *Client) DetectDominantContext(ctx context.Context, text string) (Detection, error) {
+	sampler := c.dominantSampler
+	if sampler == nil {
+		sampler = defaultDominantSampler
+	}
+
+	samples := sampler(text)
+	if len(samples) == 0 && strings.TrimSpace(text) != "" {
+		samples = []string{text}
+	}
+
+	type tally struct {
+		votes      int
+		confidence float64
+	}
+
+	var order []string
+
+	tallies := make(map[string]*tally)
+
+	for _, sample := range samples {
+		detections, err := c.DetectContext(ctx, sample)
+		if err != nil {
+			return Detection{}, err
+		}
+
+		best, ok := topDetection(detections)
+		if !ok {
+			continue
+		}
+
+		t, ok := tallies[best.Language]
+		if !ok {
+			t = &tally{}
+			tallies[best.Language] = t
+			order = append(order, best.Language)
+		}
+
+		t.votes++
+		t.confidence += best.Confidence
+	}
+
+	var dominant Detection
+
+	bestVotes, bestConfidence := 0, 0.0
+	for _, language := range order {
+		t := tallies[language]
+		if t.votes > bestVotes || t.votes == bestVotes && t.confidence > bestConfidence {
+			bestVotes, bestConfidence = t.votes, t.confidence
+			dominant = Detection{Confidence: t.confidence / float64(t.votes), Language: language}
+		}
+	}
+
+	return dominant, nil
+}
+
+// LanguageShare represents the share of a text written in a language.
+type LanguageShare struct {
+	// Language code
+	Language string
+	// Fraction of the characters of the text detected in the language
+	Fraction float64
+	// Average confidence of the detections of the language
+	Confidence float64
+}
+
+// DetectMixed detects the languages of a text that may mix several of them,
+// such as code-switched messages. The text is split into segments with the
+// given Splitter (SplitSentences if nil), each segment is detected separately,
+// and the languages are returned by decreasing share of the text.
+func (c *Client) DetectMixed(text string, split Splitter) ([]LanguageShare, error) {
+	return c.DetectMixedContext(context.Background(), text, split)
+}
+
+// DetectMixedContext is like DetectMixed but uses the given context for the requests.
+func (c *Client) DetectMixedContext(ctx context.Context, text string, split Splitter) ([]LanguageShare, error) {
+	if split == nil {
+		split = SplitSentences
+	}
+
+	var (
+		shares []*LanguageShare
+		counts []int
+		total  int
+	)
+
+	index := make(map[string]int)
+
+	for _, segment := range split(text) {
+		detections, err := c.DetectContext(ctx, segment)
+		if err != nil {
+			return nil, err
+		}
+
+		best, ok := topDetection(detections)
+		if !ok {
+			continue
+		}
+
+		i, ok := index[best.Language]
+		if !ok {
+			i = len(shares)
+			index[best.Language] = i
+			shares = append(shares, &LanguageShare{Language: best.Language})
+			counts = append(counts, 0)
+		}
+
+		length := utf8.RuneCountInString(segment)
+		shares[i].Fraction += float64(length)
+		shares[i].Confidence += best.Confidence
+		counts[i]++
+		total += length
+	}
+
+	result := make([]LanguageShare, len(shares))
+	for i, share := range shares {
+		result[i] = LanguageShare{
+			Language:   share.Language,
+			Fraction:   share.Fraction / float64(total),
+			Confidence: share.Confidence / float64(counts[i]),
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Fraction > result[j].Fraction
+	})
+
+	return result, nil
+}
+
+// WithConfidenceScale sets the scale of the detection confidences reported by
+// the server, which the client converts to the range from 0 to 1 of
+// Detection.Confidence. LibreTranslate reports percentages, so the default
+// scale is 100; set 1 for instances reporting fractions.
+//
+// The scale is checked once per response: if any confidence of a response is
+// above the scale, all the confidences of that response are read as
+// percentages, since a fraction cannot exceed 1. Values out of range are
+// clamped.
+func WithConfidenceScale(scale float64) Option {
+	return func(c *Client) {
+		c.confidenceScale = scale
+	}
+}
+
+// normalizeConfidences converts the confidences of the detections of a
+// response to the range from 0 to 1, with the same scale for all of them.
+func (c *Client) normalizeConfidences(lists ...[]Detection) {
+	scale := c.confidenceScale
+	if scale <= 0 {
+		scale = 100
+	}
+
+	for _, detections := range lists {
+		for _, detection := range detections {
+			if detection.Confidence > scale {
+				scale = 100
+			}
+		}
+	}
+
+	for _, detections := range lists {
+		for i := range detections {
+			detections[i].Confidence = min(max(detections[i].Confidence/scale, 0), 1)
+		}
+	}
+}
+
+// ErrLowConfidence is returned by DetectAndDispatch when the language of the
+// text is not detected with enough confidence.
+var ErrLowConfidence = errors.New("low detection confidence")
+
+// DetectAndDispatch detects the language of a text and calls dispatch with the
+// best detection, such as to route the text to a language-specific content
+// filter. The detection is returned along with the error of dispatch, if any,
+// for logging.
+//
+// When no language is detected, or the best detection has a confidence below
+// minConfidence (from 0 to 1, like Detection.Confidence), dispatch is not
+// called and an error wrapping ErrLowConfidence is returned with the best
+// detection, if any, so the caller can fall back to a generic handling.
+func (c *Client) DetectAndDispatch(
+	ctx context.Context,
+	q string,
+	minConfidence float64,
+	dispatch func(Detection) error,
+) (Detection, error) {
+	detections, err := c.DetectContext(ctx, q)
+	if err != nil {
+		return Detection{}, err
+	}
+
+	best, ok := topDetection(detections)
+	if !ok {
+		return Detection{}, fmt.Errorf("%w: no language detected", ErrLowConfidence)
+	}
+
+	if best.Confidence < minConfidence {
+		return best, fmt.Errorf("%w: %s detected with confidence %.2f", ErrLowConfidence, best.Language, best.Confidence)
+	}
+
+	return best, dispatch(best)
+}
+
+// topDetection returns the detection with the highest confidence, if any.
+func topDetection(detections []Detection) (Detection, bool) {
+	if len(detections) == 0 {
+		return Detection{}, false
+	}
+
+	best := detections[0]
+	for _, detection := range detections[1:] {
+		if detection.Confidence > best.Confidence {
+			best = detection
+		}
+	}
+
+	return best, true
+}
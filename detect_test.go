@@ -0,0 +1,107 @@
+package libretranslate
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDetectConfidenceScale(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		opts     []Option
+		want     []float64
+	}{
+		{"percentages", `[{"confidence":90,"language":"en"},{"confidence":1.0,"language":"fr"}]`, nil, []float64{0.9, 0.01}},
+		{"low percentage alone", `[{"confidence":1.0,"language":"en"}]`, nil, []float64{0.01}},
+		{"half percent", `[{"confidence":0.5,"language":"en"}]`, nil, []float64{0.005}},
+		{"fractions", `[{"confidence":0.9,"language":"en"},{"confidence":0.1,"language":"fr"}]`, []Option{WithConfidenceScale(1)}, []float64{0.9, 0.1}},
+		{"percentages on a fraction scale", `[{"confidence":90,"language":"en"},{"confidence":0.5,"language":"fr"}]`, []Option{WithConfidenceScale(1)}, []float64{0.9, 0.005}},
+		{"clamped", `[{"confidence":250,"language":"en"},{"confidence":-3,"language":"fr"}]`, nil, []float64{1, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.response))
+			}))
+			defer srv.Close()
+
+			c := NewClientWithBaseURL(srv.URL, "key", tt.opts...)
+
+			detections, err := c.Detect("text")
+			if err != nil {
+				t.Fatalf("Detect: %v", err)
+			}
+
+			if len(detections) != len(tt.want) {
+				t.Fatalf("got %d detections, want %d", len(detections), len(tt.want))
+			}
+
+			for i, want := range tt.want {
+				if got := detections[i].Confidence; math.Abs(got-want) > 1e-9 {
+					t.Errorf("detection %d: got confidence %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectAndDispatchLowPercentage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"confidence":1.0,"language":"en"}]`))
+	}))
+	defer srv.Close()
+
+	c := NewClientWithBaseURL(srv.URL, "key")
+
+	dispatched := false
+
+	_, err := c.DetectAndDispatch(context.Background(), "text", 0.5, func(Detection) error {
+		dispatched = true
+		return nil
+	})
+	if !errors.Is(err, ErrLowConfidence) || dispatched {
+		t.Errorf("a 1%% detection was dispatched (err %v), want ErrLowConfidence", err)
+	}
+}
+
+func TestDetectStrictDecoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		opts     []Option
+		wantErr  bool
+	}{
+		{"unknown field", `[{"confidence":90,"language":"en","bogus":1}]`, []Option{WithStrictDecoding()}, true},
+		{"unknown field without strict decoding", `[{"confidence":90,"language":"en","bogus":1}]`, nil, false},
+		{"known fields", `[{"confidence":90,"language":"sr","script":"Cyrillic"}]`, []Option{WithStrictDecoding()}, false},
+		{"language code", `["en"]`, []Option{WithStrictDecoding()}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.response))
+			}))
+			defer srv.Close()
+
+			c := NewClientWithBaseURL(srv.URL, "key", tt.opts...)
+
+			_, err := c.Detect("text")
+
+			var decodeErr *DecodeError
+			if tt.wantErr && !errors.As(err, &decodeErr) {
+				t.Errorf("got error %v, want a *DecodeError", err)
+			}
+
+			if !tt.wantErr && err != nil {
+				t.Errorf("Detect: %v", err)
+			}
+		})
+	}
+}
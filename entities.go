@@ -0,0 +1,57 @@
+package libretranslate
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// entityPattern matches the HTML character references ended by a semicolon:
+// named ("&amp;"), decimal ("&#39;") and hexadecimal ("&#x27;").
+var entityPattern = regexp.MustCompile(`&(?:[A-Za-z][A-Za-z0-9]{1,31}|#[0-9]{1,7}|#[xX][0-9A-Fa-f]{1,6});`)
+
+// WithEntityDecoding makes the client decode the HTML character references,
+// such as "&amp;" or "&#39;", that some instances return in the translations
+// of plain text, where they are not markup. It only applies to the
+// translations requested with the "text" format (the default); HTML
+// translations are left as they are.
+//
+// The decoding is conservative: only references ended by a semicolon and
+// known to HTML are decoded, and a reference found in the original text is
+// kept as it is everywhere in the translation, since the text was meant to
+// contain it.
+func WithEntityDecoding() Option {
+	return func(c *Client) {
+		c.entityDecoding = true
+	}
+}
+
+// decodeEntities decodes the HTML character references of the translations of
+// a text, if the client decodes them and the text was translated as plain text.
+func (c *Client) decodeEntities(query string, result *TranslateResult, opts callOptions) {
+	if !c.entityDecoding || opts.format != "" && opts.format != "text" {
+		return
+	}
+
+	result.TranslatedText = decodeTextEntities(query, result.TranslatedText)
+
+	for i, alternative := range result.Alternatives {
+		result.Alternatives[i] = decodeTextEntities(query, alternative)
+	}
+}
+
+// decodeTextEntities decodes the character references of a translation that
+// are not in the original text.
+func decodeTextEntities(query, translated string) string {
+	if !strings.Contains(translated, "&") {
+		return translated
+	}
+
+	return entityPattern.ReplaceAllStringFunc(translated, func(entity string) string {
+		if strings.Contains(query, entity) {
+			return entity
+		}
+
+		return html.UnescapeString(entity)
+	})
+}
@@ -0,0 +1,266 @@
+package libretranslate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Errors returned (wrapped in an *APIError) when the server rejects a request.
+var (
+	ErrMissingQuery            = errors.New("missing text to translate")
+	ErrInvalidSource           = errors.New("invalid source language")
+	ErrInvalidTarget           = errors.New("invalid target language")
+	ErrInvalidFormat           = errors.New("unsupported text format")
+	ErrTextLimitExceeded       = errors.New("text limit exceeded")
+	ErrInvalidAPIKey           = errors.New("invalid API key")
+	ErrUnsupportedLanguagePair = errors.New("unsupported language pair")
+)
+
+// ErrEmptyTranslation is returned, wrapped in an *APIError for responses with
+// no content, when the server returns no translation and the client was
+// created with WithErrorOnEmptyResult.
+var ErrEmptyTranslation = errors.New("empty translation")
+
+// LanguagePairError reports a source and target languages that are supported
+// separately but cannot be translated into each other. It is wrapped in an
+// *APIError and matched by errors.Is for ErrUnsupportedLanguagePair.
+type LanguagePairError struct {
+	// Source language code of the request
+	Source string
+	// Target language code of the request
+	Target string
+}
+
+func (e *LanguagePairError) Error() string {
+	return fmt.Sprintf("unsupported language pair: %s to %s", e.Source, e.Target)
+}
+
+// Is reports whether target is ErrUnsupportedLanguagePair.
+func (e *LanguagePairError) Is(target error) bool {
+	return target == ErrUnsupportedLanguagePair
+}
+
+// ErrConnection is matched by errors.Is for every *ConnectionError.
+var ErrConnection = errors.New("connection error")
+
+// ConnectionError reports a failure to reach the server, such as a DNS
+// resolution failure, a refused connection or a timeout, as opposed to an
+// error response from the API.
+type ConnectionError struct {
+	// Underlying error returned by the HTTP client
+	Err error
+}
+
+func (e *ConnectionError) Error() string {
+	return "connection error: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrConnection.
+func (e *ConnectionError) Is(target error) bool {
+	return target == ErrConnection
+}
+
+// sendError wraps an error returned by the HTTP client in a *ConnectionError,
+// unless the request was canceled by its context.
+func sendError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+
+	return &ConnectionError{Err: err}
+}
+
+// BatchError reports the items of a batch operation that failed or did not
+// complete. The results of the other items are returned along with it.
+type BatchError struct {
+	// Errors of the failed items, by index
+	Errors map[int]error
+	// Number of items in the batch
+	Total int
+}
+
+func (e *BatchError) Error() string {
+	indexes := e.indexes()
+	if len(indexes) == 0 {
+		return fmt.Sprintf("batch error: 0 of %d items failed", e.Total)
+	}
+
+	return fmt.Sprintf(
+		"batch error: %d of %d items failed, first at index %d: %s",
+		len(indexes),
+		e.Total,
+		indexes[0],
+		e.Errors[indexes[0]],
+	)
+}
+
+// Unwrap returns the errors of the failed items, in order of index.
+func (e *BatchError) Unwrap() []error {
+	indexes := e.indexes()
+
+	errs := make([]error, len(indexes))
+	for i, index := range indexes {
+		errs[i] = e.Errors[index]
+	}
+
+	return errs
+}
+
+// indexes returns the sorted indexes of the failed items.
+func (e *BatchError) indexes() []int {
+	indexes := make([]int, 0, len(e.Errors))
+	for index := range e.Errors {
+		indexes = append(indexes, index)
+	}
+
+	slices.Sort(indexes)
+
+	return indexes
+}
+
+// DecodeError reports a response body that could not be decoded, either
+// because it is malformed or because it was truncated, for instance when the
+// connection dropped while it was being read.
+type DecodeError struct {
+	// Path of the endpoint the response came from
+	Endpoint string
+	// Number of bytes of the body read before the error
+	BytesRead int64
+	// Whether the body ended before a complete JSON value
+	Truncated bool
+	// Underlying error
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Truncated {
+		return fmt.Sprintf("decode error: %s: response truncated after %d bytes: %s", e.Endpoint, e.BytesRead, e.Err)
+	}
+
+	return fmt.Sprintf("decode error: %s: failed after %d bytes: %s", e.Endpoint, e.BytesRead, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// APIError represents an error response from the LibreTranslate API.
+//
+// Known messages are mapped to one of the Err* variables above, which can be
+// checked with errors.Is. An unsupported language pair is also reported as a
+// *LanguagePairError, which can be retrieved with errors.As.
+type APIError struct {
+	// HTTP status code of the response
+	StatusCode int
+	// Error message sent by the server (empty if it could not be decoded)
+	Message string
+	// Source language of the request, if any
+	Source string
+	// Target language of the request, if any
+	Target string
+	// Beginning of the (first) text of the request, truncated to 40
+	// characters
+	Query string
+
+	kind   error
+	header http.Header
+	// detailed makes Error report the request (see WithErrorContext)
+	detailed bool
+}
+
+// errorSnippetLength is the number of characters of the text of a request
+// kept in an *APIError.
+const errorSnippetLength = 40
+
+// newAPIError returns an *APIError for the given message, classified using the request parameters.
+func newAPIError(statusCode int, message string, params url.Values) *APIError {
+	err := &APIError{
+		StatusCode: statusCode,
+		Message:    message,
+		kind:       classifyMessage(message, params),
+	}
+	err.setRequest(params)
+
+	return err
+}
+
+// setRequest sets the languages and the text of the request. The API key is
+// never kept.
+func (e *APIError) setRequest(params url.Values) {
+	e.Source = params.Get("source")
+	e.Target = params.Get("target")
+	e.Query = params.Get("q")
+
+	if runes := []rune(e.Query); len(runes) > errorSnippetLength {
+		e.Query = string(runes[:errorSnippetLength]) + "…"
+	}
+}
+
+func (e *APIError) Error() string {
+	var msg string
+	if e.Message == "" {
+		msg = fmt.Sprintf(
+			"API error: non-ok response (%d) from the API and failed to decode error message",
+			e.StatusCode,
+		)
+	} else {
+		msg = fmt.Sprintf("API error: code %d - %s", e.StatusCode, e.Message)
+	}
+
+	if !e.detailed || e.Source == "" && e.Target == "" && e.Query == "" {
+		return msg
+	}
+
+	return fmt.Sprintf("%s (source %q, target %q, q %q)", msg, e.Source, e.Target, e.Query)
+}
+
+// Unwrap returns the known error the message was mapped to, if any.
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
+
+// classifyMessage maps a server error message to a known error. The server
+// reports unsupported source and target languages with the same message, so
+// the language in the message is compared against the request parameters,
+// which also give the languages of an unsupported pair.
+func classifyMessage(message string, params url.Values) error {
+	lower := strings.ToLower(message)
+
+	switch {
+	case strings.Contains(lower, "missing q parameter"):
+		return ErrMissingQuery
+	case strings.Contains(lower, "missing source parameter"):
+		return ErrInvalidSource
+	case strings.Contains(lower, "missing target parameter"):
+		return ErrInvalidTarget
+	case strings.Contains(lower, "format is not supported"):
+		return ErrInvalidFormat
+	case strings.Contains(lower, "exceeds text limit"),
+		strings.Contains(lower, "exceeds character limit"):
+		return ErrTextLimitExceeded
+	case strings.Contains(lower, "api key"):
+		return ErrInvalidAPIKey
+	case strings.Contains(lower, "is not available as a target language from"):
+		return &LanguagePairError{Source: params.Get("source"), Target: params.Get("target")}
+	case strings.HasSuffix(lower, " is not supported"):
+		switch strings.TrimSuffix(message, " is not supported") {
+		case params.Get("source"):
+			return ErrInvalidSource
+		case params.Get("target"):
+			return ErrInvalidTarget
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package libretranslate
+
+import (
+	"maps"
+	"sort"
+	"strings"
+)
+
+// OtherFamily is the group of the languages missing from the family mapping.
+const OtherFamily = "Other"
+
+// languageFamilies maps language codes to the name of their family (or of
+// their branch, for the large Indo-European family), to group languages in
+// pickers. The API does not report the families, so this list is the default
+// mapping of GroupLanguagesByFamily.
+var languageFamilies = map[string]string{
+	// Indo-European branches
+	"af": "Germanic", "da": "Germanic", "de": "Germanic", "en": "Germanic",
+	"fy": "Germanic", "is": "Germanic", "lb": "Germanic", "nb": "Germanic",
+	"nl": "Germanic", "nn": "Germanic", "no": "Germanic", "sv": "Germanic",
+	"yi": "Germanic",
+	"ca": "Romance", "es": "Romance", "fr": "Romance", "gl": "Romance",
+	"it": "Romance", "oc": "Romance", "pt": "Romance", "ro": "Romance",
+	"be": "Slavic", "bg": "Slavic", "bs": "Slavic", "cs": "Slavic",
+	"hr": "Slavic", "mk": "Slavic", "pl": "Slavic", "ru": "Slavic",
+	"sk": "Slavic", "sl": "Slavic", "sr": "Slavic", "uk": "Slavic",
+	"lt": "Baltic", "lv": "Baltic",
+	"br": "Celtic", "cy": "Celtic", "ga": "Celtic", "gd": "Celtic",
+	"el": "Hellenic",
+	"sq": "Albanian",
+	"hy": "Armenian",
+	"bn": "Indo-Iranian", "ckb": "Indo-Iranian", "fa": "Indo-Iranian",
+	"gu": "Indo-Iranian", "hi": "Indo-Iranian", "ku": "Indo-Iranian",
+	"mr": "Indo-Iranian", "ne": "Indo-Iranian", "pa": "Indo-Iranian",
+	"ps": "Indo-Iranian", "si": "Indo-Iranian", "tg": "Indo-Iranian",
+	"ur": "Indo-Iranian",
+	// Other families
+	"am": "Afro-Asiatic", "ar": "Afro-Asiatic", "ha": "Afro-Asiatic",
+	"he": "Afro-Asiatic", "mt": "Afro-Asiatic", "so": "Afro-Asiatic",
+	"km": "Austroasiatic", "vi": "Austroasiatic",
+	"id": "Austronesian", "jv": "Austronesian", "mg": "Austronesian",
+	"mi": "Austronesian", "ms": "Austronesian", "tl": "Austronesian",
+	"eo": "Constructed", "ia": "Constructed",
+	"kn": "Dravidian", "ml": "Dravidian", "ta": "Dravidian", "te": "Dravidian",
+	"ja": "Japonic",
+	"ka": "Kartvelian",
+	"ko": "Koreanic",
+	"eu": "Language isolate",
+	"mn": "Mongolic",
+	"ig": "Niger-Congo", "sw": "Niger-Congo", "xh": "Niger-Congo",
+	"yo": "Niger-Congo", "zu": "Niger-Congo",
+	"bo": "Sino-Tibetan", "my": "Sino-Tibetan", "zh": "Sino-Tibetan",
+	"lo": "Tai-Kadai", "th": "Tai-Kadai",
+	"az": "Turkic", "kk": "Turkic", "ky": "Turkic", "tk": "Turkic",
+	"tr": "Turkic", "tt": "Turkic", "ug": "Turkic", "uz": "Turkic",
+	"et": "Uralic", "fi": "Uralic", "hu": "Uralic",
+}
+
+// LanguageFamilies returns a copy of the built-in mapping of language codes
+// to family names, for instance to extend it with custom languages and pass it
+// to GroupLanguagesByFamily.
+func LanguageFamilies() map[string]string {
+	return maps.Clone(languageFamilies)
+}
+
+// LanguageGroup represents the languages of a family.
+type LanguageGroup struct {
+	// Name of the family, or OtherFamily
+	Family string
+	// Languages of the family, in the order they were given
+	Languages []Language
+}
+
+// GroupLanguagesByFamily groups languages, such as the result of
+// GetLanguages, by family, for a language picker. The families come from the
+// given mapping of language codes to family names, or from the built-in one
+// (see LanguageFamilies) if it is nil. Region and script subtags, as in
+// "pt-BR", are ignored when the full code is missing from the mapping.
+//
+// The groups are sorted by family name, followed by the OtherFamily group
+// holding the languages missing from the mapping, if any.
+func GroupLanguagesByFamily(languages []Language, families map[string]string) []LanguageGroup {
+	if families == nil {
+		families = languageFamilies
+	}
+
+	var (
+		groups []LanguageGroup
+		other  []Language
+	)
+
+	index := make(map[string]int)
+
+	for _, language := range languages {
+		family, ok := families[language.Code]
+		if !ok {
+			base, _, _ := strings.Cut(strings.ToLower(language.Code), "-")
+			family, ok = families[base]
+		}
+
+		if !ok || family == "" || family == OtherFamily {
+			other = append(other, language)
+			continue
+		}
+
+		i, ok := index[family]
+		if !ok {
+			i = len(groups)
+			index[family] = i
+			groups = append(groups, LanguageGroup{Family: family})
+		}
+
+		groups[i].Languages = append(groups[i].Languages, language)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Family < groups[j].Family
+	})
+
+	if len(other) > 0 {
+		groups = append(groups, LanguageGroup{Family: OtherFamily, Languages: other})
+	}
+
+	return groups
+}
@@ -0,0 +1,69 @@
+package libretranslate
+
+import "testing"
+
+func TestGroupLanguagesByFamily(t *testing.T) {
+	languages := []Language{{Code: "es"}, {Code: "ja"}, {Code: "pt-BR"}, {Code: "tlh"}, {Code: "fr"}}
+
+	custom := LanguageFamilies()
+	custom["tlh"] = "Constructed"
+	custom["ja"] = OtherFamily
+
+	tests := []struct {
+		name     string
+		families map[string]string
+		want     map[string][]string
+		order    []string
+	}{
+		{
+			"built-in mapping",
+			nil,
+			map[string][]string{"Japonic": {"ja"}, "Romance": {"es", "pt-BR", "fr"}, OtherFamily: {"tlh"}},
+			[]string{"Japonic", "Romance", OtherFamily},
+		},
+		{
+			"custom mapping",
+			custom,
+			map[string][]string{"Constructed": {"tlh"}, "Romance": {"es", "pt-BR", "fr"}, OtherFamily: {"ja"}},
+			[]string{"Constructed", "Romance", OtherFamily},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := GroupLanguagesByFamily(languages, tt.families)
+
+			if len(groups) != len(tt.order) {
+				t.Fatalf("got %d groups, want %d", len(groups), len(tt.order))
+			}
+
+			for i, group := range groups {
+				if group.Family != tt.order[i] {
+					t.Errorf("group %d: got family %q, want %q", i, group.Family, tt.order[i])
+				}
+
+				var codes []string
+				for _, language := range group.Languages {
+					codes = append(codes, language.Code)
+				}
+
+				want := tt.want[group.Family]
+				if len(codes) != len(want) {
+					t.Errorf("%s: got %v, want %v", group.Family, codes, want)
+					continue
+				}
+
+				for j := range codes {
+					if codes[j] != want[j] {
+						t.Errorf("%s: got %v, want %v", group.Family, codes, want)
+						break
+					}
+				}
+			}
+		})
+	}
+
+	if _, ok := LanguageFamilies()["tlh"]; ok {
+		t.Error("modifying the copy returned by LanguageFamilies changed the built-in mapping")
+	}
+}
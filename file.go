@@ -0,0 +1,196 @@
+package libretranslate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"slices"
+)
+
+// ErrFileTooLarge is returned by TranslateFile when the file is larger than
+// the file size limit of the instance, without uploading it.
+var ErrFileTooLarge = errors.New("file too large")
+
+// WithFileSizeLimit sets the maximum size in bytes of the files uploaded by
+// TranslateFile, instead of the limit reported in the settings of the
+// instance. A negative limit disables the check.
+func WithFileSizeLimit(limit int64) Option {
+	return func(c *Client) {
+		c.fileSizeLimit = limit
+	}
+}
+
+// FileSizeLimit returns the maximum size in bytes of the files the instance
+// accepts for translation, or 0 if it is unknown. The limit set with
+// WithFileSizeLimit wins; otherwise it is read from the settings, which are
+// fetched on the first call and then read from the client cache.
+func (c *Client) FileSizeLimit(ctx context.Context) (int64, error) {
+	if c.fileSizeLimit != 0 {
+		return max(c.fileSizeLimit, 0), nil
+	}
+
+	settings, err := c.settings(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return settings.FileSizeLimit, nil
+}
+
+// fileUpload is a file sent with a multipart request.
+type fileUpload struct {
+	name string
+	data []byte
+}
+
+// requestFileKey is the context key of the file uploaded by a request.
+type requestFileKey struct{}
+
+// TranslateFile uploads a file to translate, such as a .docx or .txt
+// document, and returns the url of the translated file. The name of the file
+// tells the server its format (see Settings.SupportedFilesFormat).
+//
+// The file is read in memory, so it can be sent again when the request is
+// retried. If it is larger than the file size limit (see FileSizeLimit), the
+// call fails with an error wrapping ErrFileTooLarge, which reports the limit,
+// before the upload. When the settings of the instance cannot be fetched, the
+// limit is unknown and the file is uploaded anyway.
+func (c *Client) TranslateFile(filename string, file io.Reader, source, target string) (string, error) {
+	return c.TranslateFileContext(context.Background(), filename, file, source, target)
+}
+
+// TranslateFileContext is like TranslateFile but uses the given context for the requests.
+func (c *Client) TranslateFileContext(ctx context.Context, filename string, file io.Reader, source, target string) (string, error) {
+	limit, err := c.FileSizeLimit(ctx)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+
+		// Without the settings the limit is unknown: the server decides.
+		c.log(ctx, slog.LevelDebug, "libretranslate: file size limit unavailable", "error", err)
+		limit = 0
+	}
+
+	if size, ok := readerSize(file); ok && limit > 0 && size > limit {
+		return "", fmt.Errorf("%w: %d bytes, larger than the limit of %d bytes", ErrFileTooLarge, size, limit)
+	}
+
+	if limit > 0 {
+		file = io.LimitReader(file, limit+1)
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", fmt.Errorf("file reading error: %w", err)
+	}
+
+	if limit > 0 && int64(len(data)) > limit {
+		return "", fmt.Errorf("%w: larger than the limit of %d bytes", ErrFileTooLarge, limit)
+	}
+
+	key, err := c.apiKey(ctx, "")
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("source", source)
+	params.Set("target", target)
+	params.Set("api_key", key)
+
+	ctx = context.WithValue(ctx, requestFileKey{}, fileUpload{name: filename, data: data})
+
+	res, err := c.do(ctx, http.MethodPost, "/translate_file", params)
+	if err != nil {
+		return "", err
+	}
+
+	defer res.Body.Close()
+
+	result := struct {
+		TranslatedFileURL string `json:"translatedFileUrl"`
+	}{}
+	if err := c.decode(res, &result); err != nil {
+		return "", err
+	}
+
+	return result.TranslatedFileURL, nil
+}
+
+// readerSize returns the number of bytes left in a reader, if it is known.
+func readerSize(r io.Reader) (int64, bool) {
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len()), true
+	case io.Seeker:
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+
+		if file, ok := r.(interface{ Stat() (fs.FileInfo, error) }); ok {
+			if info, err := file.Stat(); err == nil && info.Mode().IsRegular() {
+				return info.Size() - offset, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// newMultipartRequest creates a request uploading a file along with the
+// parameters, and returns it with its Content-Type.
+func (c *Client) newMultipartRequest(ctx context.Context, uri string, params url.Values, upload fileUpload) (*http.Request, string, error) {
+	var buf bytes.Buffer
+
+	mw := multipart.NewWriter(&buf)
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		for _, value := range params[key] {
+			if err := mw.WriteField(key, value); err != nil {
+				return nil, "", fmt.Errorf("request body encoding error: %s", err)
+			}
+		}
+	}
+
+	part, err := mw.CreateFormFile("file", upload.name)
+	if err != nil {
+		return nil, "", fmt.Errorf("request body encoding error: %s", err)
+	}
+
+	if _, err := part.Write(upload.data); err != nil {
+		return nil, "", fmt.Errorf("request body encoding error: %s", err)
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, "", fmt.Errorf("request body encoding error: %s", err)
+	}
+
+	body := buf.Bytes()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, bytes.NewReader(body))
+	if err != nil {
+		return nil, "", fmt.Errorf("HTTP request creation error: %s", err)
+	}
+
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	return req, mw.FormDataContentType(), nil
+}
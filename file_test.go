@@ -0,0 +1,54 @@
+package libretranslate
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTranslateFileSizeLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings int
+		opts     []Option
+		wantErr  error
+	}{
+		{"settings unavailable", http.StatusNotFound, nil, nil},
+		{"settings failing", http.StatusInternalServerError, nil, nil},
+		{"limit from the settings", http.StatusOK, nil, ErrFileTooLarge},
+		{"limit set by the option", http.StatusNotFound, []Option{WithFileSizeLimit(4)}, ErrFileTooLarge},
+		{"check disabled", http.StatusOK, []Option{WithFileSizeLimit(-1)}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/frontend/settings":
+					w.WriteHeader(tt.settings)
+					if tt.settings == http.StatusOK {
+						w.Write([]byte(`{"fileSizeLimit":4}`))
+					} else {
+						w.Write([]byte(`{"error":"unavailable"}`))
+					}
+				case "/translate_file":
+					w.Write([]byte(`{"translatedFileUrl":"http://localhost/file.txt"}`))
+				}
+			}))
+			defer srv.Close()
+
+			c := NewClientWithBaseURL(srv.URL, "key", tt.opts...)
+
+			url, err := c.TranslateFile("file.txt", strings.NewReader("hello world"), "en", "es")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && url != "http://localhost/file.txt" {
+				t.Errorf("got url %q, want the translated file", url)
+			}
+		})
+	}
+}
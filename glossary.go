@@ -0,0 +1,143 @@
+package libretranslate
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// glossary maps source terms to the terms that must replace them in the
+// translations, loaded with LoadGlossary.
+type glossary struct {
+	// pattern matches the source terms, longest first
+	pattern *regexp.Regexp
+	terms   map[string]string
+}
+
+// LoadGlossary replaces the glossary of the client with the one read from r,
+// in the given format. The terms of the glossary are enforced in the
+// translations: each source term found in a text, matched like the terms of
+// WithDoNotTranslate (case-sensitively and as a whole word), is protected
+// from translation and replaced with its target term. A term without a target
+// term is kept untranslated. The response cache is cleared, since its
+// translations may not follow the new glossary.
+//
+// The glossary applies to every translation of the client, whatever the
+// languages: use a client per language pair with different glossaries. Clone
+// copies the glossary of the client.
+//
+// The only format supported is "csv": one term per line, with the source term
+// followed by the target term, and an optional "source,target" header. Errors
+// report the line where parsing failed.
+func (c *Client) LoadGlossary(r io.Reader, format string) error {
+	if !strings.EqualFold(format, "csv") {
+		return fmt.Errorf("glossary error: unsupported format %q", format)
+	}
+
+	terms, err := readGlossaryCSV(r)
+	if err != nil {
+		return fmt.Errorf("glossary error: %w", err)
+	}
+
+	c.glossary.Store(newGlossary(terms))
+	c.responses.clear()
+
+	return nil
+}
+
+// readGlossaryCSV reads the terms of a CSV glossary.
+func readGlossaryCSV(r io.Reader) (map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	terms := make(map[string]string)
+
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return terms, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if first && len(record) == 2 && strings.EqualFold(record[0], "source") && strings.EqualFold(record[1], "target") {
+			continue
+		}
+
+		if len(record) > 2 {
+			return nil, fmt.Errorf("line %d: expected 2 fields, got %d", line, len(record))
+		}
+
+		source := strings.TrimSpace(record[0])
+		if source == "" {
+			return nil, fmt.Errorf("line %d: empty source term", line)
+		}
+
+		target := source
+		if len(record) == 2 && strings.TrimSpace(record[1]) != "" {
+			target = strings.TrimSpace(record[1])
+		}
+
+		terms[source] = target
+	}
+}
+
+// newGlossary returns the glossary of the given terms, or nil if there are none.
+func newGlossary(terms map[string]string) *glossary {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	quoted := make([]string, 0, len(terms))
+	for source := range terms {
+		quoted = append(quoted, regexp.QuoteMeta(source))
+	}
+
+	// Longer terms first, so the leftmost match is the longest one.
+	sort.Slice(quoted, func(i, j int) bool {
+		if len(quoted[i]) != len(quoted[j]) {
+			return len(quoted[i]) > len(quoted[j])
+		}
+
+		return quoted[i] < quoted[j]
+	})
+
+	return &glossary{
+		pattern: regexp.MustCompile(strings.Join(quoted, "|")),
+		terms:   terms,
+	}
+}
+
+// find returns the spans of the source terms found as whole words in a text.
+// A nil *glossary finds nothing.
+func (g *glossary) find(text string) [][2]int {
+	if g == nil {
+		return nil
+	}
+
+	return findWholeWords(g.pattern, text)
+}
+
+// replacement returns the text replacing a protected span in the translation:
+// the target term if the span is a source term of the glossary, or the span
+// itself.
+func (g *glossary) replacement(span string) string {
+	if g == nil {
+		return span
+	}
+
+	if target, ok := g.terms[span]; ok {
+		return target
+	}
+
+	return span
+}
@@ -1,3 +1,8 @@
 module github.com/piero-vic/libretranslate
 
 go 1.21.0
+
+require (
+	golang.org/x/net v0.21.0
+	golang.org/x/text v0.14.0
+)
@@ -0,0 +1,413 @@
+package libretranslate
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// ErrMalformedHTML is reported by TranslateHTML for the chunks whose
+// translation is not well-formed although the original chunk is.
+var ErrMalformedHTML = errors.New("malformed HTML translation")
+
+var (
+	// htmlVoidElements are the elements that have no end tag.
+	htmlVoidElements = map[string]bool{
+		"area": true, "base": true, "br": true, "col": true, "embed": true,
+		"hr": true, "img": true, "input": true, "link": true, "meta": true,
+		"source": true, "track": true, "wbr": true,
+	}
+	// htmlRawTextElements are the elements whose content is not markup, and
+	// which are never translated.
+	htmlRawTextElements = map[string]bool{
+		"script": true, "style": true, "template": true, "textarea": true,
+	}
+)
+
+// HTMLResult represents the result of an HTML translation.
+type HTMLResult struct {
+	// Translated document
+	Text string
+	// Chunks of the document sent for translation, in order
+	Chunks []string
+}
+
+// TranslateHTML translates an HTML document exceeding the character limit of
+// the instance, by splitting it into chunks of whole elements translated with
+// the "html" format. An element too long for a single chunk is split into its
+// children, and a text into sentences. Comments, doctypes and the content of
+// the script, style, template and textarea elements are not translated.
+//
+// The limit is set with WithChunkSize, or taken from the settings of the
+// instance.
+func (c *Client) TranslateHTML(doc, source, target string) (string, error) {
+	return c.TranslateHTMLContext(context.Background(), doc, source, target)
+}
+
+// TranslateHTMLContext is like TranslateHTML but uses the given context for the requests.
+func (c *Client) TranslateHTMLContext(ctx context.Context, doc, source, target string) (string, error) {
+	result, err := c.TranslateHTMLDetailed(ctx, doc, source, target)
+	if err != nil {
+		return "", err
+	}
+
+	return result.Text, nil
+}
+
+// TranslateHTMLDetailed is like TranslateHTMLContext but does not stop at the
+// first failed chunk. The failed chunks are left untranslated in the
+// document, and reported by index in a *BatchError returned along with the
+// result. A chunk whose translation is not well-formed fails with
+// ErrMalformedHTML, so the document stays well-formed if it was. When the
+// context is canceled, the remaining chunks fail with the context error.
+func (c *Client) TranslateHTMLDetailed(ctx context.Context, doc, source, target string) (HTMLResult, error) {
+	limit := c.chunkSize
+	if limit <= 0 {
+		settings, err := c.settings(ctx)
+		if err != nil {
+			return HTMLResult{}, err
+		}
+
+		limit = settings.CharLimit
+	}
+
+	chunker := htmlChunker{limit: limit}
+	chunker.add(parseHTML(doc).children)
+	chunker.flush()
+
+	var (
+		b      strings.Builder
+		chunks []string
+		errs   []error
+	)
+
+	for _, piece := range chunker.pieces {
+		if !piece.translate {
+			b.WriteString(piece.text)
+			continue
+		}
+
+		chunks = append(chunks, piece.text)
+
+		translated, err := c.translateHTMLChunk(ctx, piece.text, source, target)
+		errs = append(errs, err)
+
+		if err != nil {
+			translated = piece.text
+		}
+
+		b.WriteString(translated)
+	}
+
+	return HTMLResult{Text: b.String(), Chunks: chunks}, batchError(errs)
+}
+
+// translateHTMLChunk translates a chunk of HTML, keeping the whitespace
+// surrounding it, and checks that its translation is well-formed.
+func (c *Client) translateHTMLChunk(ctx context.Context, chunk, source, target string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	lead, trail := surroundingSpace(chunk)
+	core := strings.TrimSpace(chunk)
+
+	result, err := c.translate(ctx, core, source, target, callOptions{format: "html"})
+	if err != nil {
+		return "", err
+	}
+
+	if wellFormedHTML(core) && !wellFormedHTML(result.TranslatedText) {
+		return "", ErrMalformedHTML
+	}
+
+	return lead + result.TranslatedText + trail, nil
+}
+
+// htmlChunker splits HTML nodes into chunks to translate, of up to limit
+// characters when possible, and the markup between them.
+type htmlChunker struct {
+	limit  int
+	pieces []textPiece
+	// chunk holds the nodes of the current chunk
+	chunk strings.Builder
+	size  int
+}
+
+// add adds sibling nodes to the chunks.
+func (h *htmlChunker) add(nodes []*htmlNode) {
+	for _, node := range nodes {
+		text := node.html()
+		size := utf8.RuneCountInString(text)
+
+		switch {
+		case node.verbatim:
+			h.flush()
+			h.pieces = append(h.pieces, textPiece{text: text})
+		case h.limit <= 0 || h.size+size <= h.limit:
+			h.chunk.WriteString(text)
+			h.size += size
+		case node.element && size > h.limit:
+			h.flush()
+			h.pieces = append(h.pieces, textPiece{text: node.open})
+			h.add(node.children)
+			h.flush()
+			h.pieces = append(h.pieces, textPiece{text: node.close})
+		case node.text && size > h.limit:
+			h.addText(text)
+		default:
+			// The node starts a new chunk, alone if it is too long.
+			h.flush()
+			h.chunk.WriteString(text)
+			h.size = size
+		}
+	}
+}
+
+// addText adds a text too long for the current chunk, split into sentences.
+func (h *htmlChunker) addText(text string) {
+	for _, sentence := range splitSentencesKeepSpace(text) {
+		size := utf8.RuneCountInString(sentence)
+		if h.size+size > h.limit {
+			h.flush()
+		}
+
+		h.chunk.WriteString(sentence)
+		h.size += size
+	}
+}
+
+// flush ends the current chunk. A chunk without text is kept as markup.
+func (h *htmlChunker) flush() {
+	if h.chunk.Len() == 0 {
+		return
+	}
+
+	chunk := h.chunk.String()
+	h.pieces = append(h.pieces, textPiece{text: chunk, translate: hasHTMLText(chunk)})
+	h.chunk.Reset()
+	h.size = 0
+}
+
+// htmlNode represents a node of an HTML document.
+type htmlNode struct {
+	// open holds the start tag of an element, or the whole node otherwise
+	open string
+	// close holds the end tag of an element, empty if it is missing
+	close    string
+	children []*htmlNode
+	// element reports whether the node is an element that can have children
+	element bool
+	// text reports whether the node is a text
+	text bool
+	// verbatim reports whether the node must not be translated
+	verbatim bool
+}
+
+// html returns the source of the node.
+func (n *htmlNode) html() string {
+	if !n.element {
+		return n.open
+	}
+
+	var b strings.Builder
+
+	n.write(&b)
+
+	return b.String()
+}
+
+func (n *htmlNode) write(b *strings.Builder) {
+	b.WriteString(n.open)
+
+	for _, child := range n.children {
+		child.write(b)
+	}
+
+	b.WriteString(n.close)
+}
+
+// parseHTML parses an HTML document into a tree of nodes, under a root node
+// without tags. An end tag closes the elements left open since the matching
+// start tag, and a stray end tag is kept as a leaf node.
+func parseHTML(doc string) *htmlNode {
+	root := &htmlNode{element: true}
+	stack := []*htmlNode{root}
+	names := []string{""}
+
+	for _, token := range tokenizeHTML(doc) {
+		top := stack[len(stack)-1]
+
+		switch token.kind {
+		case htmlStartTag:
+			node := &htmlNode{open: token.raw, element: true}
+			top.children = append(top.children, node)
+			stack = append(stack, node)
+			names = append(names, token.name)
+		case htmlEndTag:
+			i := len(names) - 1
+			for i > 0 && names[i] != token.name {
+				i--
+			}
+
+			if i == 0 {
+				top.children = append(top.children, &htmlNode{open: token.raw})
+				continue
+			}
+
+			stack[i].close = token.raw
+			stack, names = stack[:i], names[:i]
+		default:
+			top.children = append(top.children, &htmlNode{
+				open:     token.raw,
+				text:     token.kind == htmlText,
+				verbatim: token.kind == htmlVerbatim,
+			})
+		}
+	}
+
+	return root
+}
+
+// wellFormedHTML reports whether every end tag of an HTML text closes the
+// latest element left open, and every element is closed.
+func wellFormedHTML(text string) bool {
+	var open []string
+
+	for _, token := range tokenizeHTML(text) {
+		switch token.kind {
+		case htmlStartTag:
+			open = append(open, token.name)
+		case htmlEndTag:
+			if len(open) == 0 || open[len(open)-1] != token.name {
+				return false
+			}
+
+			open = open[:len(open)-1]
+		}
+	}
+
+	return len(open) == 0
+}
+
+// hasHTMLText reports whether an HTML text has text outside of its tags.
+func hasHTMLText(text string) bool {
+	for _, token := range tokenizeHTML(text) {
+		if token.kind == htmlText && strings.TrimSpace(token.raw) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// htmlTokenKind is the kind of an HTML token.
+type htmlTokenKind int
+
+const (
+	htmlText htmlTokenKind = iota
+	htmlStartTag
+	htmlEndTag
+	// htmlLeafTag is a void or self-closing tag.
+	htmlLeafTag
+	// htmlVerbatim is a comment, a doctype, a processing instruction, or a raw
+	// text element with its content.
+	htmlVerbatim
+)
+
+// htmlToken represents a token of an HTML text.
+type htmlToken struct {
+	kind htmlTokenKind
+	// name holds the lowercased name of a tag
+	name string
+	raw  string
+}
+
+// tokenizeHTML splits an HTML text into tokens with the tokenizer of
+// golang.org/x/net/html, keeping the source of each token. A '<' that does
+// not start a tag, and a tag cut by the end of the text, are part of the text.
+func tokenizeHTML(text string) []htmlToken {
+	var tokens []htmlToken
+
+	z := html.NewTokenizer(strings.NewReader(text))
+
+	// verbatim holds the raw text element being read, and depth the number
+	// of elements of the same name open inside it.
+	var (
+		verbatim *htmlToken
+		depth    int
+	)
+
+	for {
+		kind := z.Next()
+		raw := string(z.Raw())
+		name, _ := z.TagName()
+
+		if kind == html.ErrorToken {
+			if verbatim != nil {
+				verbatim.raw += raw
+				tokens = append(tokens, *verbatim)
+			} else if raw != "" {
+				tokens = appendHTMLText(tokens, raw)
+			}
+
+			return tokens
+		}
+
+		if verbatim != nil {
+			// Keep the element with its content, up to its end tag.
+			verbatim.raw += raw
+
+			switch {
+			case kind == html.StartTagToken && string(name) == verbatim.name:
+				depth++
+			case kind == html.EndTagToken && string(name) == verbatim.name && depth > 0:
+				depth--
+			case kind == html.EndTagToken && string(name) == verbatim.name:
+				tokens = append(tokens, *verbatim)
+				verbatim = nil
+			}
+
+			continue
+		}
+
+		switch kind {
+		case html.TextToken:
+			tokens = appendHTMLText(tokens, raw)
+		case html.StartTagToken:
+			token := htmlToken{kind: htmlStartTag, name: string(name), raw: raw}
+
+			switch {
+			case htmlRawTextElements[token.name]:
+				token.kind = htmlVerbatim
+				verbatim = &token
+			case htmlVoidElements[token.name]:
+				token.kind = htmlLeafTag
+				tokens = append(tokens, token)
+			default:
+				tokens = append(tokens, token)
+			}
+		case html.SelfClosingTagToken:
+			tokens = append(tokens, htmlToken{kind: htmlLeafTag, name: string(name), raw: raw})
+		case html.EndTagToken:
+			tokens = append(tokens, htmlToken{kind: htmlEndTag, name: string(name), raw: raw})
+		default:
+			// Comments, doctypes and processing instructions.
+			tokens = append(tokens, htmlToken{kind: htmlVerbatim, raw: raw})
+		}
+	}
+}
+
+// appendHTMLText appends a text to the tokens, merging it with the previous
+// token if it is a text too.
+func appendHTMLText(tokens []htmlToken, text string) []htmlToken {
+	if n := len(tokens); n > 0 && tokens[n-1].kind == htmlText {
+		tokens[n-1].raw += text
+		return tokens
+	}
+
+	return append(tokens, htmlToken{kind: htmlText, raw: text})
+}
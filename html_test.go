@@ -0,0 +1,98 @@
+package libretranslate
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTokenizeHTML(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		kinds []htmlTokenKind
+	}{
+		{"text", "a < b", []htmlTokenKind{htmlText}},
+		{"element", `<P Class='a>b'>Hi</P>`, []htmlTokenKind{htmlStartTag, htmlText, htmlEndTag}},
+		{"void and self-closing", "a<br>b<img/>", []htmlTokenKind{htmlText, htmlLeafTag, htmlText, htmlLeafTag}},
+		{"comment and doctype", "<!DOCTYPE html><!-- note --><?xml version=\"1.0\"?>", []htmlTokenKind{htmlVerbatim, htmlVerbatim, htmlVerbatim}},
+		{"script", "<script>if (a<b) {}</script>x", []htmlTokenKind{htmlVerbatim, htmlText}},
+		{"template", "<template><p>a</p><template>b</template></template>x", []htmlTokenKind{htmlVerbatim, htmlText}},
+		{"unclosed raw text element", "<style>p {}", []htmlTokenKind{htmlVerbatim}},
+		{"cut tag", "a <b", []htmlTokenKind{htmlText}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := tokenizeHTML(tt.text)
+
+			var (
+				b     strings.Builder
+				kinds []htmlTokenKind
+			)
+
+			for _, token := range tokens {
+				b.WriteString(token.raw)
+				kinds = append(kinds, token.kind)
+			}
+
+			if b.String() != tt.text {
+				t.Errorf("got source %q, want %q", b.String(), tt.text)
+			}
+
+			if len(kinds) != len(tt.kinds) {
+				t.Fatalf("got kinds %v, want %v", kinds, tt.kinds)
+			}
+
+			for i := range kinds {
+				if kinds[i] != tt.kinds[i] {
+					t.Errorf("got kinds %v, want %v", kinds, tt.kinds)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestWellFormedHTML(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"<p>a <b>b</b> c</p>", true},
+		{"<P>a</p>", true},
+		{"a<br>b<img/>", true},
+		{"<p>a <b>b</p></b>", false},
+		{"<p>a", false},
+		{"a</p>", false},
+	}
+
+	for _, tt := range tests {
+		if got := wellFormedHTML(tt.text); got != tt.want {
+			t.Errorf("wellFormedHTML(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateHTMLChunks(t *testing.T) {
+	srv := batchServer(t)
+	defer srv.Close()
+
+	c := NewClientWithBaseURL(srv.URL, "key", WithChunkSize(20))
+
+	doc := "<!-- keep --><div><p>first paragraph</p>\n<p>second one</p></div><script>var a = 1;</script>"
+
+	result, err := c.TranslateHTMLDetailed(context.Background(), doc, "en", "es")
+	if err != nil {
+		t.Fatalf("TranslateHTMLDetailed: %v", err)
+	}
+
+	want := "<!-- keep --><div><p>FIRST PARAGRAPH</p>\n<P>SECOND ONE</P></div><script>var a = 1;</script>"
+	if result.Text != want {
+		t.Errorf("got %q, want %q", result.Text, want)
+	}
+
+	if len(result.Chunks) != 2 {
+		t.Errorf("got chunks %q, want 2", result.Chunks)
+	}
+}
@@ -0,0 +1,125 @@
+package libretranslate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// MeasureLatency measures the round trip time of a lightweight request to the
+// instance. The request is not retried, so the result reflects a single round
+// trip bounded by the context deadline.
+//
+// It can be used to pick the fastest of several instances.
+func (c *Client) MeasureLatency(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+
+	if err := c.ping(ctx, c.backends.next()); err != nil {
+		return 0, err
+	}
+
+	return time.Since(start), nil
+}
+
+// Warmup opens up to conns connections (at least one) to each backend of the
+// client by sending lightweight requests concurrently, so that the first
+// requests made afterwards do not pay for the connection and TLS handshakes.
+//
+// The connections are kept by the transport of the http.Client, so conns
+// should not exceed its idle connection limit per host (2 by default).
+func (c *Client) Warmup(ctx context.Context, conns int) error {
+	backends := c.backends.all()
+	errs := make([]error, len(backends)*max(conns, 1))
+
+	var wg sync.WaitGroup
+
+	for i := range errs {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			backend := backends[i%len(backends)]
+			if err := c.ping(ctx, backend); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", c.baseURLFor(backend), err)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("warmup error: %w", err)
+	}
+
+	return nil
+}
+
+// ping sends a single lightweight request to the given backend, without
+// retries, and reads the whole response so the connection can be reused.
+func (c *Client) ping(ctx context.Context, backend *backendState) error {
+	if err := c.lifecycle.begin(); err != nil {
+		return err
+	}
+
+	defer c.lifecycle.end()
+
+	ctx, cancel := c.lifecycle.bind(ctx)
+	defer cancel()
+
+	params := url.Values{}
+
+	req, err := c.buildRequest(ctx, backend, http.MethodGet, "/frontend/settings", params)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.handle(req)
+	if err != nil {
+		return err
+	}
+
+	if err := checkForResponseErrors(res, params); err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	_, err = io.Copy(io.Discard, res.Body)
+
+	return err
+}
+
+// VerifyKey checks whether the instance accepts the API key of the client.
+// It returns nil if the key is accepted and an error wrapping ErrInvalidAPIKey
+// if it is not.
+//
+// The check sends a detection request without any text: the server validates
+// the key before the parameters, so an accepted key is answered with a
+// missing parameter error and no text is processed.
+func (c *Client) VerifyKey(ctx context.Context) error {
+	key, err := c.apiKey(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("api_key", key)
+
+	res, err := c.do(ctx, http.MethodPost, "/detect", params)
+	if err == nil {
+		res.Body.Close()
+		return nil
+	}
+
+	if errors.Is(err, ErrMissingQuery) {
+		return nil
+	}
+
+	return err
+}
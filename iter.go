@@ -0,0 +1,60 @@
+//go:build go1.23
+
+package libretranslate
+
+import (
+	"context"
+	"iter"
+)
+
+// TranslateSeq returns an iterator over the translations of several texts,
+// yielding the index of each text and its result in order, along with a
+// function returning the error that stopped the iteration, if any.
+//
+// The texts are translated lazily, one batch request at a time, in chunks
+// that fit the character limit (see WithChunkSize), so breaking out of the
+// loop stops sending requests.
+//
+//	seq, errFn := client.TranslateSeq(ctx, queries, "en", "es")
+//	for i, result := range seq {
+//		...
+//	}
+//	if err := errFn(); err != nil {
+//		...
+//	}
+func (c *Client) TranslateSeq(ctx context.Context, queries []string, source, target string, opts ...CallOption) (iter.Seq2[int, TranslateResult], func() error) {
+	var err error
+
+	seq := func(yield func(int, TranslateResult) bool) {
+		err = nil
+
+		limit := c.chunkSize
+		if limit <= 0 {
+			var settings Settings
+			if settings, err = c.settings(ctx); err != nil {
+				return
+			}
+
+			limit = settings.CharLimit
+		}
+
+		index := 0
+
+		for _, chunk := range ChunkBatch(queries, limit) {
+			var results []TranslateResult
+			if results, err = c.translateBatch(ctx, chunk, source, target, newCallOptions(opts)); err != nil {
+				return
+			}
+
+			for _, result := range results {
+				if !yield(index, result) {
+					return
+				}
+
+				index++
+			}
+		}
+	}
+
+	return seq, func() error { return err }
+}
@@ -2,48 +2,318 @@ package libretranslate
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"maps"
 	"net/http"
 	"net/url"
+	"os"
 	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/text/encoding"
 )
 
 // DefaultBaseURL contains the default base url for the LibreTranslate API.
 const DefaultBaseURL = "https://libretranslate.com"
 
+// DefaultContentType contains the default Content-Type for POST requests.
+const DefaultContentType = "application/x-www-form-urlencoded"
+
 // Client handles the interaction with the LibreTranslate API.
 type Client struct {
 	baseUrl string
 	token   string
 	client  *http.Client
+	// httpClient is the http.Client given to WithHTTPClient, which client
+	// copies to apply the transport options
+	httpClient *http.Client
+
+	// baseURI is baseUrl parsed once by the constructor instead of for
+	// every request.
+	baseURI    *url.URL
+	baseURIErr error
+
+	contentType    string
+	methodOverride bool
+	retry          retryPolicy
+
+	compressThreshold   int
+	compressionRejected atomic.Bool
+
+	// glossary is replaced by LoadGlossary while the client is in use
+	glossary atomic.Pointer[glossary]
+
+	strictLanguagePair bool
+	skipKeys           *regexp.Regexp
+	skipPattern        *regexp.Regexp
+	placeholders       PlaceholderStyle
+	fallbackToSource   bool
+	strictDecoding     bool
+	dominantSampler    Splitter
+	similarity         SimilarityFunc
+	chunkSize          int
+	chunkOverlap       int
+	keys               *keyProvider
+	preserveWhitespace bool
+	pivotLanguage      string
+	maxResponseBytes   int64
+	arrayEncoding      ArrayEncoding
+	memory             TMStore
+	paramNames         map[string]string
+	middleware         []Middleware
+	inputCharset       encoding.Encoding
+	lineByLine         bool
+	idleConnTimeout    time.Duration
+	minTLSVersion      uint16
+	timeout            time.Duration
+	keepClientTimeout  bool
+	retryResultPattern *regexp.Regexp
+	maxBatchItems      int
+	streamThreshold    int
+	userAgent          string
+	apiBasePath        string
+	entityDecoding     bool
+	fileSizeLimit      int64
+	confidenceScale    float64
+	rtlLanguages       map[string]bool
+	signer             func(*http.Request) error
+	doNotTranslate     *regexp.Regexp
+	errorOnEmpty       bool
+	logger             *slog.Logger
+	handle             Handler
+	endpointTimeouts   map[string]time.Duration
+	versionDetection   bool
+	authPlacements     map[string]AuthPlacement
+	qualityThreshold   *float64
+	errorContext       bool
+
+	detectionCleaner       func(string) string
+	minDetectionConfidence float64
+
+	backends  *backendPool
+	cache     cache
+	responses *responseCache
+	lifecycle lifecycle
 }
 
 // NewClient returns a new API client with the given token.
-func NewClient(token string) *Client {
-	return &Client{
-		baseUrl: DefaultBaseURL,
-		token:   token,
-		client:  http.DefaultClient,
-	}
+func NewClient(token string, opts ...Option) *Client {
+	return NewClientWithBaseURL(DefaultBaseURL, token, opts...)
 }
 
 // NewClientWithBaseURL returns a new API client with the given token.
-func NewClientWithBaseURL(baseURL string, token string) *Client {
-	return &Client{
-		baseUrl: baseURL,
-		token:   token,
-		client:  http.DefaultClient,
+func NewClientWithBaseURL(baseURL string, token string, opts ...Option) *Client {
+	c := &Client{
+		baseUrl:     baseURL,
+		token:       token,
+		contentType: DefaultContentType,
+		middleware:  []Middleware{CheckResponseErrors},
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	c.baseURI, c.baseURIErr = url.Parse(c.baseUrl)
+	c.client = c.configureTransport()
+	c.handle = c.handler()
+
+	return c
+}
+
+// Clone returns a new client with the configuration of c, including its
+// http.Client, with the given options applied on top.
+//
+// The caches are not shared: the clone starts with empty language, settings
+// and response caches (of the same size), and with fresh backend health, since
+// the options may point it to another instance or key.
+func (c *Client) Clone(opts ...Option) *Client {
+	clone := &Client{
+		baseUrl:                c.baseUrl,
+		token:                  c.token,
+		client:                 c.client,
+		httpClient:             c.httpClient,
+		contentType:            c.contentType,
+		methodOverride:         c.methodOverride,
+		retry:                  c.retry,
+		compressThreshold:      c.compressThreshold,
+		strictLanguagePair:     c.strictLanguagePair,
+		skipKeys:               c.skipKeys,
+		skipPattern:            c.skipPattern,
+		placeholders:           c.placeholders,
+		fallbackToSource:       c.fallbackToSource,
+		strictDecoding:         c.strictDecoding,
+		dominantSampler:        c.dominantSampler,
+		similarity:             c.similarity,
+		chunkSize:              c.chunkSize,
+		chunkOverlap:           c.chunkOverlap,
+		keys:                   c.keys.clone(),
+		preserveWhitespace:     c.preserveWhitespace,
+		pivotLanguage:          c.pivotLanguage,
+		maxResponseBytes:       c.maxResponseBytes,
+		arrayEncoding:          c.arrayEncoding,
+		memory:                 c.memory,
+		paramNames:             c.paramNames,
+		middleware:             c.middleware,
+		inputCharset:           c.inputCharset,
+		lineByLine:             c.lineByLine,
+		idleConnTimeout:        c.idleConnTimeout,
+		minTLSVersion:          c.minTLSVersion,
+		timeout:                c.timeout,
+		keepClientTimeout:      c.keepClientTimeout,
+		retryResultPattern:     c.retryResultPattern,
+		maxBatchItems:          c.maxBatchItems,
+		streamThreshold:        c.streamThreshold,
+		userAgent:              c.userAgent,
+		apiBasePath:            c.apiBasePath,
+		entityDecoding:         c.entityDecoding,
+		fileSizeLimit:          c.fileSizeLimit,
+		confidenceScale:        c.confidenceScale,
+		rtlLanguages:           c.rtlLanguages,
+		signer:                 c.signer,
+		doNotTranslate:         c.doNotTranslate,
+		errorOnEmpty:           c.errorOnEmpty,
+		logger:                 c.logger,
+		endpointTimeouts:       maps.Clone(c.endpointTimeouts),
+		versionDetection:       c.versionDetection,
+		authPlacements:         maps.Clone(c.authPlacements),
+		qualityThreshold:       c.qualityThreshold,
+		errorContext:           c.errorContext,
+		detectionCleaner:       c.detectionCleaner,
+		minDetectionConfidence: c.minDetectionConfidence,
+		backends:               c.backends.clone(),
+		responses:              c.responses.clone(),
+	}
+
+	clone.glossary.Store(c.glossary.Load())
+
+	for _, opt := range opts {
+		opt(clone)
+	}
+
+	clone.baseURI, clone.baseURIErr = url.Parse(clone.baseUrl)
+	clone.handle = clone.handler()
+
+	// Keep sharing the connections of c unless the transport options changed.
+	if clone.httpClient != c.httpClient ||
+		clone.idleConnTimeout != c.idleConnTimeout ||
+		clone.minTLSVersion != c.minTLSVersion ||
+		clone.timeout != c.timeout ||
+		clone.keepClientTimeout != c.keepClientTimeout {
+		clone.client = clone.configureTransport()
+	}
+
+	return clone
+}
+
+// Environment variables read by NewClientFromEnv.
+const (
+	EnvAPIKey  = "LIBRETRANSLATE_API_KEY"
+	EnvBaseURL = "LIBRETRANSLATE_BASE_URL"
+)
+
+// NewClientFromEnv returns a new API client configured from the environment.
+//
+// The token is read from LIBRETRANSLATE_API_KEY and the base url from
+// LIBRETRANSLATE_BASE_URL, which defaults to DefaultBaseURL. Since the default
+// instance requires an API key, an error is returned if the base url is not
+// set and the API key is missing.
+func NewClientFromEnv(opts ...Option) (*Client, error) {
+	token := os.Getenv(EnvAPIKey)
+	baseURL := os.Getenv(EnvBaseURL)
+
+	if baseURL == "" {
+		if token == "" {
+			return nil, fmt.Errorf("environment error: %s is required when %s is not set", EnvAPIKey, EnvBaseURL)
+		}
+
+		baseURL = DefaultBaseURL
+	}
+
+	return NewClientWithBaseURL(baseURL, token, opts...), nil
 }
 
 // Detection represents the result of a dectection query.
 type Detection struct {
-	// Confidence value
+	// Confidence value, from 0 to 1 (see WithConfidenceScale)
 	Confidence float64 `json:"confidence"`
 	// Language code
 	Language string `json:"language"`
+	// Writing system of the text, such as "Latin" or "Cyrillic" (only if
+	// reported by the server)
+	Script string `json:"script,omitempty"`
+}
+
+// UnmarshalJSON decodes a detection given as an object, or as a language
+// code, as reported by some older servers, in which case the confidence is zero.
+func (d *Detection) UnmarshalJSON(data []byte) error {
+	return decodeDetection(data, d, false)
+}
+
+// decodeDetection decodes a detection like Detection.UnmarshalJSON, rejecting
+// the unknown fields of an object if strict. The client decodes detections
+// with it, since the decoder set up by WithStrictDecoding does not apply
+// inside UnmarshalJSON methods.
+func decodeDetection(data []byte, d *Detection, strict bool) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		var language string
+		if err := json.Unmarshal(data, &language); err != nil {
+			return err
+		}
+
+		*d = Detection{Language: language}
+
+		return nil
+	}
+
+	// The conversion drops the methods, so the object is decoded as usual.
+	type detection Detection
+
+	return decodeJSON(data, (*detection)(d), strict)
+}
+
+// decodeJSON decodes a JSON value, rejecting unknown fields if strict.
+func decodeJSON(data []byte, v any, strict bool) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+
+	return dec.Decode(v)
+}
+
+// detections holds the detections of a /detect response. The strictness of
+// the client must be set before decoding.
+type detections struct {
+	list   []Detection
+	strict bool
+}
+
+// UnmarshalJSON decodes an array of detections.
+func (l *detections) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+
+	l.list = make([]Detection, len(items))
+	for i, item := range items {
+		if err := decodeDetection(item, &l.list[i], l.strict); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // Language represents the result for the languages query.
@@ -52,139 +322,880 @@ type Language struct {
 	Code string `json:"code"`
 	// Human-readable language name (in English)
 	Name string `json:"name"`
+	// Codes of the languages it can be translated into
+	Targets []string `json:"targets"`
 }
 
 // TranslateResult represents the result for a translation query.
 type TranslateResult struct {
 	// Detected language information (only for auto detect)
 	DetectedLanguage Detection `json:"detectedLanguage"`
+	// Other candidates for the detected language, best first (only for auto
+	// detect, if reported by the server)
+	DetectionAlternatives []Detection `json:"-"`
+	// Alternative translations (only if requested)
+	Alternatives []string `json:"alternatives"`
+	// Engine used for the translation (only if reported by the server)
+	Engine string `json:"engine"`
+	// Original text, as given to the client
+	Source string `json:"-"`
 	// Translated text
 	TranslatedText string `json:"translatedText"`
+	// Problems found by the client while processing the translation
+	Warnings []string `json:"-"`
+	// Whether the original text was returned because the translation failed
+	Fallback bool `json:"-"`
+	// Whether the text was translated through the pivot language
+	Pivoted bool `json:"-"`
+	// Detected language of the original text, when it was replaced by the
+	// detection of the cleaned text (see WithCleanInputForDetection)
+	InitialDetection *Detection `json:"-"`
+	// Wall-clock time of the call, including retries (set by TranslateDetailed)
+	Duration time.Duration `json:"-"`
+
+	// Headers of the response the result was decoded from (nil if the
+	// result did not come from a request)
+	header http.Header
+}
+
+// translateResponse represents the response of the server for a translation query.
+type translateResponse struct {
+	DetectedLanguage detectionList `json:"detectedLanguage"`
+	Alternatives     []string      `json:"alternatives"`
+	Engine           string        `json:"engine"`
+	TranslatedText   string        `json:"translatedText"`
+}
+
+// detectionList holds a detected language, which servers report either as a
+// single detection or as an array of candidates, best first. The strictness
+// of the client must be set before decoding.
+type detectionList struct {
+	list   []Detection
+	strict bool
+}
+
+// UnmarshalJSON decodes a single detection or an array of detections.
+func (l *detectionList) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		l.list = nil
+		return nil
+	}
+
+	if bytes.HasPrefix(data, []byte("[")) {
+		list := detections{strict: l.strict}
+		if err := list.UnmarshalJSON(data); err != nil {
+			return err
+		}
+
+		l.list = list.list
+
+		return nil
+	}
+
+	var detection Detection
+	if err := decodeDetection(data, &detection, l.strict); err != nil {
+		return err
+	}
+
+	l.list = []Detection{detection}
+
+	return nil
+}
+
+// split returns the best detection and the other candidates.
+func (l detectionList) split() (Detection, []Detection) {
+	if len(l.list) == 0 {
+		return Detection{}, nil
+	}
+
+	if len(l.list) == 1 {
+		return l.list[0], nil
+	}
+
+	return l.list[0], l.list[1:]
+}
+
+// detectionLists holds the detected languages of a batch translation. The
+// strictness of the client must be set before decoding.
+type detectionLists struct {
+	lists  []detectionList
+	strict bool
+}
+
+// UnmarshalJSON decodes an array holding a detected language for each text.
+func (l *detectionLists) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+
+	l.lists = make([]detectionList, len(items))
+	for i, item := range items {
+		l.lists[i].strict = l.strict
+		if err := l.lists[i].UnmarshalJSON(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // Detect makes a request to detects the language of a given text.
 func (c *Client) Detect(q string) ([]Detection, error) {
-	params := url.Values{}
-	params.Set("q", q)
-	params.Set("api_key", c.token)
+	return c.DetectContext(context.Background(), q)
+}
+
+// DetectContext is like Detect but uses the given context for the request.
+func (c *Client) DetectContext(ctx context.Context, q string) ([]Detection, error) {
+	q, err := c.decodeInput(q)
+	if err != nil {
+		return nil, err
+	}
 
-	req, err := c.buildRequest(http.MethodPost, "/detect", params)
+	key, err := c.apiKey(ctx, "")
 	if err != nil {
 		return nil, err
 	}
 
-	responseBody, err := doRequest(c.client, req)
+	params := url.Values{}
+	params.Set("q", q)
+	params.Set("api_key", key)
+
+	res, err := c.do(ctx, http.MethodPost, "/detect", params)
 	if err != nil {
 		return nil, err
 	}
 
-	defer responseBody.Close()
+	defer res.Body.Close()
+
+	result := detections{strict: c.strictDecoding}
+	if err := c.decode(res, &result); err != nil {
+		return nil, err
+	}
 
-	result := []Detection{}
-	err = json.NewDecoder(responseBody).Decode(&result)
+	c.normalizeConfidences(result.list)
 
-	return result, err
+	return result.list, nil
 }
 
 // Getlanguages makes a request to retrieve the list of supported languages.
 func (c *Client) GetLanguages() ([]Language, error) {
-	params := url.Values{}
-	params.Set("api_key", c.token)
+	return c.GetLanguagesContext(context.Background())
+}
 
-	req, err := c.buildRequest(http.MethodGet, "/languages", params)
+// GetLanguagesContext is like GetLanguages but uses the given context for the request.
+// A successful response is stored in the client cache.
+//
+// If the cached languages came with an ETag or Last-Modified header, the
+// request is conditional, and the cached languages are returned if the server
+// answers that they have not changed (304).
+func (c *Client) GetLanguagesContext(ctx context.Context) ([]Language, error) {
+	key, err := c.apiKey(ctx, "")
 	if err != nil {
 		return nil, err
 	}
 
-	responseBody, err := doRequest(c.client, req)
+	params := url.Values{}
+	params.Set("api_key", key)
+
+	conditions := c.cache.languagesConditions()
+	if conditions != nil {
+		ctx = withRequestHeader(ctx, conditions)
+	}
+
+	res, err := c.do(ctx, http.MethodGet, "/languages", params)
 	if err != nil {
 		return nil, err
 	}
 
-	defer responseBody.Close()
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified {
+		if languages, ok := c.cache.getLanguages(); ok {
+			return languages, nil
+		}
+	}
 
 	result := []Language{}
-	err = json.NewDecoder(responseBody).Decode(&result)
+	if err := c.decode(res, &result); err != nil {
+		return result, err
+	}
 
-	return result, err
+	c.cache.setLanguages(result, responseValidators(res.Header))
+
+	return result, nil
+}
+
+// GetLanguagesStream makes a request to retrieve the list of supported
+// languages and calls fn for each language as it is decoded, without holding
+// the whole list in memory. It stops at the first error returned by fn, which
+// is returned as is. The languages are not stored in the client cache.
+func (c *Client) GetLanguagesStream(fn func(Language) error) error {
+	return c.GetLanguagesStreamContext(context.Background(), fn)
+}
+
+// GetLanguagesStreamContext is like GetLanguagesStream but uses the given context for the request.
+func (c *Client) GetLanguagesStreamContext(ctx context.Context, fn func(Language) error) error {
+	key, err := c.apiKey(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("api_key", key)
+
+	res, err := c.do(ctx, http.MethodGet, "/languages", params)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	body := &countingReader{r: res.Body}
+
+	dec := json.NewDecoder(body)
+	if c.strictDecoding {
+		dec.DisallowUnknownFields()
+	}
+
+	if token, err := dec.Token(); err != nil {
+		return decodeError(res, body, err)
+	} else if token != json.Delim('[') {
+		return decodeError(res, body, fmt.Errorf("expected array, got %v", token))
+	}
+
+	for dec.More() {
+		var language Language
+		if err := dec.Decode(&language); err != nil {
+			return decodeError(res, body, err)
+		}
+
+		if err := fn(language); err != nil {
+			return err
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return decodeError(res, body, err)
+	}
+
+	return nil
+}
+
+// GetLanguagesSorted returns the supported languages sorted by name, or by
+// code if byName is false, for display. The languages are taken from the
+// client cache, and fetched if they are not cached yet.
+//
+// Names are compared by Unicode code points, which suits the English names
+// returned by the server.
+func (c *Client) GetLanguagesSorted(ctx context.Context, byName bool) ([]Language, error) {
+	languages, err := c.languages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(languages, func(a, b Language) int {
+		if byName {
+			return strings.Compare(a.Name, b.Name)
+		}
+
+		return strings.Compare(a.Code, b.Code)
+	})
+
+	return languages, nil
 }
 
 // Translate makes a request to translate a given text from one language to another.
 func (c *Client) Translate(query, source, target string) (string, error) {
+	return c.TranslateContext(context.Background(), query, source, target)
+}
+
+// TranslateContext is like Translate but uses the given context for the request.
+//
+// If the client was created with WithFallbackToSource, a failed translation
+// returns the original text and a nil error.
+func (c *Client) TranslateContext(ctx context.Context, query, source, target string) (string, error) {
+	result, err := c.TranslateDetailed(ctx, query, source, target)
+	if err != nil && !result.Fallback {
+		return "", err
+	}
+
+	return result.TranslatedText, nil
+}
+
+// TranslateBytes is like Translate but takes and returns byte slices, for
+// pipelines working with bytes. The text is still copied into the request, so
+// it saves conversions in the calling code rather than allocations.
+func (c *Client) TranslateBytes(query []byte, source, target string) ([]byte, error) {
+	return c.TranslateBytesContext(context.Background(), query, source, target)
+}
+
+// TranslateBytesContext is like TranslateBytes but uses the given context for the request.
+func (c *Client) TranslateBytesContext(ctx context.Context, query []byte, source, target string) ([]byte, error) {
+	translated, err := c.TranslateContext(ctx, string(query), source, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(translated), nil
+}
+
+// TranslateDetailed is like TranslateContext but returns the full result of
+// the translation, including the detected language and any warnings.
+//
+// If the client was created with WithFallbackToSource, a failed translation
+// returns a result holding the original text, with Fallback set, along with
+// the error that caused it.
+func (c *Client) TranslateDetailed(ctx context.Context, query, source, target string, opts ...CallOption) (TranslateResult, error) {
+	start := time.Now()
+
+	result, err := c.translate(ctx, query, source, target, newCallOptions(opts))
+	if err != nil && c.fallbackToSource {
+		result = TranslateResult{
+			Source:         query,
+			TranslatedText: query,
+			Warnings:       []string{"translation failed, the original text was returned: " + err.Error()},
+			Fallback:       true,
+		}
+	}
+
+	result.Duration = time.Since(start)
+
+	return result, err
+}
+
+// TranslateWithResponse is like TranslateDetailed but also returns the headers
+// of the HTTP response, such as request ids or cache status added by the
+// deployment. The headers are nil if no request was made, for instance when
+// the result came from the response cache.
+func (c *Client) TranslateWithResponse(ctx context.Context, query, source, target string, opts ...CallOption) (TranslateResult, http.Header, error) {
+	result, err := c.TranslateDetailed(ctx, query, source, target, opts...)
+
+	return result, result.header, err
+}
+
+// Candidate is a possible translation of a text.
+type Candidate struct {
+	// Translated text
+	Text string
+	// Score of the translation, higher is better (0 if the server does not
+	// score translations)
+	Score float64
+}
+
+// TranslateRanked translates a given text requesting the given number of
+// alternatives, and returns the translation followed by the alternatives.
+//
+// LibreTranslate does not score its translations, so the candidates are
+// returned in the order given by the server, the primary translation first,
+// and their Score is 0.
+func (c *Client) TranslateRanked(ctx context.Context, query, source, target string, alternatives int, opts ...CallOption) ([]Candidate, error) {
+	callOptions := newCallOptions(opts)
+	callOptions.alternatives = alternatives
+
+	result, err := c.translate(ctx, query, source, target, callOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]Candidate, 0, len(result.Alternatives)+1)
+	candidates = append(candidates, Candidate{Text: result.TranslatedText})
+
+	for _, alternative := range result.Alternatives {
+		candidates = append(candidates, Candidate{Text: alternative})
+	}
+
+	return candidates, nil
+}
+
+// translate translates a given text and returns the full result.
+//
+// When the source and target languages are the same (and the source is not
+// "auto"), the text is returned unchanged without making a request, unless
+// the client was created with WithoutSameLanguagePassthrough.
+func (c *Client) translate(ctx context.Context, query, source, target string, opts callOptions) (TranslateResult, error) {
+	if c.isPassthrough(source, target) {
+		return passthroughResult(query, source), nil
+	}
+
+	if c.lineByLine && strings.Contains(strings.TrimSpace(query), "\n") {
+		return c.translateLines(ctx, query, source, target, opts)
+	}
+
+	key := newResponseKey(ctx, query, source, target, opts)
+	if result, ok := c.responses.get(key); ok {
+		return result, nil
+	}
+
+	recalled, ok := c.recall(ctx, query, source, target)
+	if ok {
+		return recalled, nil
+	}
+
+	text, err := c.decodeInput(query)
+	if err != nil {
+		return TranslateResult{}, err
+	}
+
+	protected := c.protectPlaceholders(text)
+
+	result, err := c.sendTranslate(ctx, protected.text, source, target, opts)
+	if errors.Is(err, ErrUnsupportedLanguagePair) && c.pivotLanguage != "" {
+		result, err = c.translatePivoted(ctx, protected.text, source, target, opts, err)
+	}
+
+	if err == nil && source == "auto" {
+		result, err = c.redetect(ctx, query, protected.text, target, result, opts)
+	}
+
+	if err == nil {
+		err = c.checkEmpty(text, result.TranslatedText)
+	}
+
+	if err != nil {
+		return TranslateResult{}, err
+	}
+
+	result.TranslatedText, result.Warnings = protected.restore(result.TranslatedText)
+	result.TranslatedText = c.preserveSpace(query, result.TranslatedText)
+	result.Source = query
+	result.Warnings = append(result.Warnings, recalled.Warnings...)
+	c.memorize(ctx, &result, source, target)
+	c.responses.add(key, result)
+
+	return result, nil
+}
+
+// checkEmpty returns ErrEmptyTranslation if the translation of a text that is
+// not empty is empty and the client treats empty results as errors.
+func (c *Client) checkEmpty(query, translated string) error {
+	if c.errorOnEmpty && strings.TrimSpace(translated) == "" && strings.TrimSpace(query) != "" {
+		return ErrEmptyTranslation
+	}
+
+	return nil
+}
+
+// isTranslateEndpoint reports whether endpoint returns translations.
+func isTranslateEndpoint(endpoint string) bool {
+	return endpoint == "/translate" || endpoint == "/translate_file"
+}
+
+// preserveSpace gives a translation the surrounding whitespace of the original
+// text, if the client preserves whitespace.
+func (c *Client) preserveSpace(query, translated string) string {
+	if !c.preserveWhitespace {
+		return translated
+	}
+
+	lead, trail := surroundingSpace(query)
+
+	return lead + strings.TrimSpace(translated) + trail
+}
+
+// sendTranslate makes a request to translate a given text, sent again while
+// its translation matches the result pattern of the client.
+func (c *Client) sendTranslate(ctx context.Context, query, source, target string, opts callOptions) (TranslateResult, error) {
+	results, err := c.retryOnResult(ctx, func() ([]TranslateResult, error) {
+		result, err := c.sendTranslateOnce(ctx, query, source, target, opts)
+		return []TranslateResult{result}, err
+	})
+	if err != nil {
+		return TranslateResult{}, err
+	}
+
+	return results[0], nil
+}
+
+// sendTranslateOnce makes a request to translate a given text, without
+// retrying on the result pattern.
+func (c *Client) sendTranslateOnce(ctx context.Context, query, source, target string, opts callOptions) (TranslateResult, error) {
+	res, err := c.postTranslate(ctx, query, source, target, opts)
+	if err != nil {
+		return TranslateResult{}, err
+	}
+
+	defer res.Body.Close()
+
+	response := translateResponse{DetectedLanguage: detectionList{strict: c.strictDecoding}}
+	if err := c.decode(res, &response); err != nil {
+		return TranslateResult{}, err
+	}
+
+	result := TranslateResult{
+		Alternatives:   response.Alternatives,
+		Engine:         response.Engine,
+		TranslatedText: response.TranslatedText,
+		header:         res.Header,
+	}
+	c.normalizeConfidences(response.DetectedLanguage.list)
+	result.DetectedLanguage, result.DetectionAlternatives = response.DetectedLanguage.split()
+	c.decodeEntities(query, &result, opts)
+
+	return result, nil
+}
+
+// TranslateRaw makes a request to translate a given text and returns the JSON
+// response of the server untouched, to decode fields this package does not
+// support yet. The structure of the response is defined by the server and
+// may change between versions.
+//
+// The text is sent as it is: the client features working on the text or the
+// result, such as the caches and placeholder protection, do not apply.
+func (c *Client) TranslateRaw(ctx context.Context, query, source, target string, opts ...CallOption) (json.RawMessage, error) {
+	res, err := c.postTranslate(ctx, query, source, target, newCallOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	var raw json.RawMessage
+	if err := c.decode(res, &raw); err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// postTranslate sends a request to translate a given text and returns the
+// successful response.
+func (c *Client) postTranslate(ctx context.Context, query, source, target string, opts callOptions) (*http.Response, error) {
+	key, err := c.apiKey(ctx, opts.apiKey)
+	if err != nil {
+		return nil, err
+	}
+
 	params := url.Values{}
 	params.Set("q", query)
 	params.Set("source", source)
 	params.Set("target", target)
-	params.Set("api_key", c.token)
+	params.Set("api_key", key)
+	opts.setParams(params)
+	c.adaptParams(ctx, params)
 
-	req, err := c.buildRequest(http.MethodPost, "/translate", params)
-	if err != nil {
-		return "", err
+	return c.do(ctx, http.MethodPost, "/translate", params)
+}
+
+// isPassthrough reports whether a translation between the given languages can be skipped.
+func (c *Client) isPassthrough(source, target string) bool {
+	return source == target && source != "auto" && !c.strictLanguagePair
+}
+
+// passthroughResult returns the result of a skipped translation.
+func passthroughResult(query, language string) TranslateResult {
+	return TranslateResult{
+		DetectedLanguage: Detection{Confidence: 1, Language: language},
+		Source:           query,
+		TranslatedText:   query,
 	}
+}
 
-	responseBody, err := doRequest(c.client, req)
+// EndpointURL returns the url the requests to the given endpoint (such as
+// "/translate") are sent to, without sending anything. With WithBackends, it
+// uses the backend of the latest request, or the base url given to the
+// constructor if no request was made yet.
+func (c *Client) EndpointURL(endpoint string) (string, error) {
+	uri, err := c.endpointURI(c.backends.last(), endpoint)
 	if err != nil {
 		return "", err
 	}
 
-	defer responseBody.Close()
+	return uri.String(), nil
+}
 
-	result := TranslateResult{}
-	if err := json.NewDecoder(responseBody).Decode(&result); err != nil {
-		return "", err
+// endpointURI returns the url of the given endpoint on the given backend.
+func (c *Client) endpointURI(backend *backendState, endpoint string) (*url.URL, error) {
+	base, err := c.baseURIFor(backend)
+	if err != nil {
+		return nil, fmt.Errorf("URL parsing error: %s", err)
 	}
 
-	return result.TranslatedText, nil
+	uri := *base
+	uri.Path = path.Join("/", uri.Path, c.apiBasePath, endpoint)
+
+	return &uri, nil
 }
 
-// buildRequest constructs an HTTP request with the specified HTTP method, endpoint, and parameters.
-func (c *Client) buildRequest(method, endpoint string, params url.Values) (*http.Request, error) {
-	uri, err := url.Parse(c.baseUrl)
+// buildRequest constructs an HTTP request to the given backend (or the base url
+// of the client if nil) with the specified HTTP method, endpoint, and parameters.
+func (c *Client) buildRequest(ctx context.Context, backend *backendState, method, endpoint string, params url.Values) (*http.Request, error) {
+	// The original parameters are used by CheckResponseErrors.
+	ctx = context.WithValue(ctx, requestParamsKey{}, params)
+
+	uri, err := c.endpointURI(backend, endpoint)
 	if err != nil {
-		return nil, fmt.Errorf("URL parsing error: %s", err)
+		return nil, err
+	}
+
+	override := ""
+	if method == http.MethodGet && c.methodOverride {
+		override, method = method, http.MethodPost
 	}
 
-	uri.Path = path.Join(uri.Path, endpoint)
+	header := make(http.Header)
+	params = c.placeAPIKey(method, uri, header, params)
 
-	req, err := http.NewRequest(method, uri.String(), bytes.NewBufferString(params.Encode()))
+	// Servers disagree on how form-encoded bodies carry an array of texts, so
+	// batch requests are sent as JSON unless an array encoding is set.
+	params, contentType, err := c.encodeArrays(params, c.contentType)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request creation error: %s", err)
+		return nil, fmt.Errorf("request body encoding error: %s", err)
+	}
+
+	params = c.renameParams(params)
+
+	if method != http.MethodPost {
+		contentType = DefaultContentType
+	}
+
+	var req *http.Request
+
+	upload, isUpload := ctx.Value(requestFileKey{}).(fileUpload)
+
+	if method == http.MethodPost && isUpload {
+		req, contentType, err = c.newMultipartRequest(ctx, uri.String(), params, upload)
+	} else if method == http.MethodPost && c.streamThreshold > 0 && paramsSize(params) > c.streamThreshold {
+		req, err = c.newStreamingRequest(ctx, method, uri.String(), contentType, params)
+	} else {
+		req, err = c.newBufferedRequest(ctx, method, uri.String(), contentType, params)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	if extra, ok := ctx.Value(requestHeaderKey{}).(http.Header); ok {
+		for name, values := range extra {
+			req.Header[name] = values
+		}
 	}
 
 	if method == http.MethodPost {
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	if override != "" {
+		req.Header.Set("X-HTTP-Method-Override", override)
+	}
+
+	if c.signer != nil {
+		if err := c.signer(req); err != nil {
+			return nil, fmt.Errorf("request signing error: %w", err)
+		}
+
+		// The signer may have read the body to hash it.
+		req.Body, _ = req.GetBody()
 	}
 
 	return req, nil
 }
 
-// doRequest makes an HTTP request and returns the response body.
-func doRequest(client *http.Client, req *http.Request) (io.ReadCloser, error) {
-	res, err := client.Do(req)
+// newBufferedRequest creates a request whose body is encoded in memory.
+func (c *Client) newBufferedRequest(ctx context.Context, method, uri, contentType string, params url.Values) (*http.Request, error) {
+	body, err := encodeBody(contentType, params)
 	if err != nil {
+		return nil, fmt.Errorf("request body encoding error: %s", err)
+	}
+
+	compressed := false
+	if method == http.MethodPost {
+		body, compressed = c.compressBody(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, uri, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request creation error: %s", err)
+	}
+
+	// The transport replays the body when it retries a request on a stale
+	// connection or follows a 307/308 redirect, so every request must be able
+	// to regenerate its body. Retries made by the client build a new request.
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	return req, nil
+}
+
+// bufferPool holds the buffers used to encode request bodies.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// maxPooledBufferSize is the capacity above which a buffer is not returned to
+// the pool, so a single huge request does not pin its memory.
+const maxPooledBufferSize = 64 << 10
+
+// encodeBody encodes the parameters of a POST request according to the given Content-Type.
+//
+// The body is encoded in a pooled buffer and copied out, so the buffer is
+// never retained by the request.
+func encodeBody(contentType string, params url.Values) ([]byte, error) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			bufferPool.Put(buf)
+		}
+	}()
+
+	if !isJSONContentType(contentType) {
+		encodeForm(buf, params)
+
+		return bytes.Clone(buf.Bytes()), nil
+	}
+
+	fields := make(map[string]any, len(params))
+	for key, values := range params {
+		if len(values) == 1 {
+			fields[key] = values[0]
+		} else {
+			fields[key] = values
+		}
+	}
+
+	if err := json.NewEncoder(buf).Encode(fields); err != nil {
 		return nil, err
 	}
 
-	return checkForResponseErrors(res)
+	return bytes.Clone(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
+}
+
+// encodeForm writes the parameters in URL-encoded form, sorted by key, like url.Values.Encode.
+func encodeForm(buf *bytes.Buffer, params url.Values) {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		escapedKey := url.QueryEscape(key)
+
+		for _, value := range params[key] {
+			if buf.Len() > 0 {
+				buf.WriteByte('&')
+			}
+
+			buf.WriteString(escapedKey)
+			buf.WriteByte('=')
+			buf.WriteString(url.QueryEscape(value))
+		}
+	}
+}
+
+// isJSONContentType reports whether the given Content-Type denotes a JSON body.
+func isJSONContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+
+	return strings.EqualFold(mediaType, "application/json") ||
+		len(mediaType) > len("+json") && strings.EqualFold(mediaType[len(mediaType)-len("+json"):], "+json")
+}
+
+// notModified reports whether a response answers a conditional request
+// with a 304 status, which is not an error.
+func notModified(res *http.Response) bool {
+	if res.StatusCode != http.StatusNotModified || res.Request == nil {
+		return false
+	}
+
+	return res.Request.Header.Get("If-None-Match") != "" || res.Request.Header.Get("If-Modified-Since") != ""
+}
+
+// decode decodes a JSON response body into v, rejecting unknown fields if the
+// client was created with WithStrictDecoding. Failures are reported as a
+// *DecodeError.
+func (c *Client) decode(res *http.Response, v any) error {
+	body := &countingReader{r: res.Body}
+
+	dec := json.NewDecoder(body)
+	if c.strictDecoding {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(v); err != nil {
+		return decodeError(res, body, err)
+	}
+
+	return nil
+}
+
+// decodeError returns a *DecodeError for an error decoding the body of a response.
+func decodeError(res *http.Response, body *countingReader, err error) error {
+	endpoint := ""
+	if res.Request != nil {
+		endpoint = res.Request.URL.Path
+	}
+
+	return &DecodeError{
+		Endpoint:  endpoint,
+		BytesRead: body.n,
+		Truncated: errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF),
+		Err:       err,
+	}
+}
+
+// countingReader counts the bytes read from a reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+
+	return n, err
 }
 
 type apiError struct {
 	Error string `json:"error"`
 }
 
-// checkForResponseErrors checks an HTTP response for errors and returns the response body.
-func checkForResponseErrors(res *http.Response) (io.ReadCloser, error) {
-	if res.StatusCode != http.StatusOK {
+// checkForResponseErrors checks an HTTP response for errors, closing its body if there are any.
+// The request parameters are used to tell apart errors that share the same message.
+func checkForResponseErrors(res *http.Response, params url.Values) error {
+	if res.StatusCode != http.StatusOK && !notModified(res) {
+		defer func() {
+			// Drain the body so the connection can be reused.
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}()
+
 		var result apiError
 		if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
-			return nil, fmt.Errorf(
-				"API error: non-ok response (%d) from the API and failed to decode error message",
-				res.StatusCode,
-			)
+			if errors.Is(err, ErrResponseTooLarge) {
+				return err
+			}
+
+			apiErr := &APIError{StatusCode: res.StatusCode, header: res.Header}
+			apiErr.setRequest(params)
+
+			return apiErr
 		}
 
-		return nil, fmt.Errorf("API error: code %d - %s", res.StatusCode, result.Error)
+		apiErr := newAPIError(res.StatusCode, result.Error, params)
+		apiErr.header = res.Header
+
+		return apiErr
 	}
 
-	return res.Body, nil
+	return nil
 }
@@ -0,0 +1,120 @@
+package libretranslate
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTranslateStrictDecoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		opts     []Option
+		wantErr  bool
+	}{
+		{"unknown detection field", `{"translatedText":"hola","detectedLanguage":{"confidence":90,"language":"en","bogus":1}}`, []Option{WithStrictDecoding()}, true},
+		{"unknown field in the candidates", `{"translatedText":"hola","detectedLanguage":[{"confidence":90,"language":"en"},{"confidence":5,"language":"fr","bogus":1}]}`, []Option{WithStrictDecoding()}, true},
+		{"unknown detection field without strict decoding", `{"translatedText":"hola","detectedLanguage":{"confidence":90,"language":"en","bogus":1}}`, nil, false},
+		{"known fields", `{"translatedText":"hola","detectedLanguage":[{"confidence":90,"language":"en"},{"confidence":5,"language":"fr"}]}`, []Option{WithStrictDecoding()}, false},
+		{"language code", `{"translatedText":"hola","detectedLanguage":"en"}`, []Option{WithStrictDecoding()}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.response))
+			}))
+			defer srv.Close()
+
+			c := NewClientWithBaseURL(srv.URL, "key", tt.opts...)
+
+			result, err := c.TranslateDetailed(context.Background(), "hello", "auto", "es")
+
+			var decodeErr *DecodeError
+			if tt.wantErr && !errors.As(err, &decodeErr) {
+				t.Errorf("got error %v, want a *DecodeError", err)
+			}
+
+			if !tt.wantErr && (err != nil || result.DetectedLanguage.Language != "en") {
+				t.Errorf("got %+v, %v, want a detection of en", result, err)
+			}
+		})
+	}
+}
+
+func TestTranslateBatchStrictDecoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		wantErr  bool
+	}{
+		{"unknown detection field", `{"translatedText":["hola","adiós"],"detectedLanguage":[{"confidence":90,"language":"en"},{"confidence":90,"language":"en","bogus":1}]}`, true},
+		{"unknown field in the candidates", `{"translatedText":["hola","adiós"],"detectedLanguage":[[{"confidence":90,"language":"en"}],[{"confidence":90,"language":"en"},{"language":"fr","bogus":1}]]}`, true},
+		{"known fields", `{"translatedText":["hola","adiós"],"detectedLanguage":[{"confidence":90,"language":"en"},[{"confidence":90,"language":"en"},{"confidence":5,"language":"fr"}]]}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.response))
+			}))
+			defer srv.Close()
+
+			c := NewClientWithBaseURL(srv.URL, "key", WithStrictDecoding())
+
+			results, err := c.TranslateBatchDetectedContext(context.Background(), []string{"hello", "goodbye"}, "es")
+
+			var decodeErr *DecodeError
+			if tt.wantErr && !errors.As(err, &decodeErr) {
+				t.Errorf("got error %v, want a *DecodeError", err)
+			}
+
+			if !tt.wantErr && (err != nil || len(results) != 2 || results[1].Detected.Language != "en") {
+				t.Errorf("got %+v, %v, want two detections of en", results, err)
+			}
+		})
+	}
+}
+
+func TestNoContent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		detect    bool
+		wantEmpty bool
+	}{
+		{"translate", nil, false, false},
+		{"translate with WithErrorOnEmptyResult", []Option{WithErrorOnEmptyResult()}, false, true},
+		{"detect with WithErrorOnEmptyResult", []Option{WithErrorOnEmptyResult()}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClientWithBaseURL(srv.URL, "", tt.opts...)
+
+			var err error
+			if tt.detect {
+				_, err = c.Detect("hello")
+			} else {
+				_, err = c.Translate("hello", "en", "es")
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNoContent {
+				t.Fatalf("got error %v, want an *APIError with status %d", err, http.StatusNoContent)
+			}
+
+			if got := errors.Is(err, ErrEmptyTranslation); got != tt.wantEmpty {
+				t.Errorf("got errors.Is(err, ErrEmptyTranslation) = %v, want %v", got, tt.wantEmpty)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package libretranslate
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrResponseTooLarge is returned when a response body exceeds the limit set
+// with WithMaxResponseBytes.
+var ErrResponseTooLarge = errors.New("response too large")
+
+// WithMaxResponseBytes makes the client stop reading response bodies larger
+// than n bytes and fail with ErrResponseTooLarge, which protects it from
+// misbehaving servers. There is no limit by default.
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Client) {
+		c.maxResponseBytes = n
+	}
+}
+
+// limitBody wraps a response body in a *limitedBody if the client limits the
+// size of the responses.
+func (c *Client) limitBody(body io.ReadCloser) io.ReadCloser {
+	if c.maxResponseBytes <= 0 {
+		return body
+	}
+
+	return &limitedBody{ReadCloser: body, remaining: c.maxResponseBytes}
+}
+
+// limitedBody is a response body failing with ErrResponseTooLarge once more
+// than a given number of bytes have been read.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	// Read one byte past the limit to tell a body of exactly the limit apart
+	// from a larger one.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+
+	if b.remaining < 0 {
+		return n + int(b.remaining), ErrResponseTooLarge
+	}
+
+	return n, err
+}
@@ -0,0 +1,61 @@
+package libretranslate
+
+import (
+	"context"
+	"strings"
+)
+
+// WithLineByLine makes the client translate multi-line texts one line at a
+// time (with a single batch request), keeping the line breaks and blank
+// lines, which the server may collapse. It suits poetry, addresses or lists,
+// but each line is translated without the context of the others, which can
+// hurt sentences spanning several lines. The texts of batch translations are
+// still sent whole.
+func WithLineByLine() Option {
+	return func(c *Client) {
+		c.lineByLine = true
+	}
+}
+
+// translateLines translates the lines of a text separately and joins their
+// translations with the original line breaks. The whitespace around each
+// line is kept. The detected language is the one of the first translated line.
+func (c *Client) translateLines(ctx context.Context, query, source, target string, opts callOptions) (TranslateResult, error) {
+	lines := strings.SplitAfter(query, "\n")
+
+	var (
+		texts   []string
+		indexes []int
+	)
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			texts = append(texts, strings.TrimSpace(line))
+			indexes = append(indexes, i)
+		}
+	}
+
+	translated, err := c.translateBatch(ctx, texts, source, target, opts)
+	if err != nil {
+		return TranslateResult{}, err
+	}
+
+	result := TranslateResult{Source: query}
+
+	for j, i := range indexes {
+		lead, trail := surroundingSpace(lines[i])
+		lines[i] = lead + translated[j].TranslatedText + trail
+		result.Warnings = append(result.Warnings, translated[j].Warnings...)
+	}
+
+	if len(translated) > 0 {
+		result.DetectedLanguage = translated[0].DetectedLanguage
+		result.DetectionAlternatives = translated[0].DetectionAlternatives
+		result.Engine = translated[0].Engine
+		result.header = translated[0].header
+	}
+
+	result.TranslatedText = strings.Join(lines, "")
+
+	return result, nil
+}
@@ -0,0 +1,188 @@
+package libretranslate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// SyncError reports the keys of a locale sync that could not be translated.
+// The translations of the other keys are returned along with it.
+type SyncError struct {
+	// Errors of the failed keys, by target language and key
+	Errors map[string]map[string]error
+}
+
+func (e *SyncError) Error() string {
+	failed := 0
+	for _, keys := range e.Errors {
+		failed += len(keys)
+	}
+
+	target, key := e.first()
+
+	return fmt.Sprintf(
+		"sync error: %d keys failed in %d target languages, first %s in %s: %s",
+		failed,
+		len(e.Errors),
+		key,
+		target,
+		e.Errors[target][key],
+	)
+}
+
+// Unwrap returns the errors of the failed keys, in order of target language and key.
+func (e *SyncError) Unwrap() []error {
+	var errs []error
+
+	for _, target := range sortedKeys(e.Errors) {
+		for _, key := range sortedKeys(e.Errors[target]) {
+			errs = append(errs, e.Errors[target][key])
+		}
+	}
+
+	return errs
+}
+
+// first returns the first failed target language and key, in sorted order.
+func (e *SyncError) first() (string, string) {
+	for _, target := range sortedKeys(e.Errors) {
+		if keys := sortedKeys(e.Errors[target]); len(keys) > 0 {
+			return target, keys[0]
+		}
+	}
+
+	return "", ""
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// SyncLocale translates the strings of a base locale, by key, into each of the
+// target languages, and returns the translations by target language and key.
+// Each target language is translated with a single batch request, like
+// TranslateMap.
+func (c *Client) SyncLocale(base map[string]string, source string, targets []string) (map[string]map[string]string, error) {
+	return c.SyncLocaleContext(context.Background(), base, nil, source, targets)
+}
+
+// SyncLocaleContext is like SyncLocale but uses the given context for the
+// requests, and only translates the keys of base missing from the existing
+// translations of each target language, which may be nil. The existing
+// translations of the keys of base are returned along with the new ones; the
+// keys missing from base are dropped.
+//
+// A failed target language does not stop the sync. If its batch request is
+// rejected by the server, its keys are sent one at a time to find the ones
+// that fail, unless the error applies to the whole request, such as an
+// invalid target language. The failed keys are reported in a *SyncError
+// returned along with the results, and are missing from them. When the
+// context is canceled, the remaining keys fail with the context error.
+func (c *Client) SyncLocaleContext(
+	ctx context.Context,
+	base map[string]string,
+	existing map[string]map[string]string,
+	source string,
+	targets []string,
+) (map[string]map[string]string, error) {
+	results := make(map[string]map[string]string, len(targets))
+	failed := make(map[string]map[string]error)
+
+	for _, target := range targets {
+		translations := make(map[string]string, len(base))
+
+		var keys, texts []string
+
+		for _, key := range sortedKeys(base) {
+			if text, ok := existing[target][key]; ok {
+				translations[key] = text
+			} else {
+				keys = append(keys, key)
+				texts = append(texts, base[key])
+			}
+		}
+
+		if errs := c.syncTexts(ctx, translations, keys, texts, source, target); len(errs) > 0 {
+			failed[target] = errs
+		}
+
+		results[target] = translations
+	}
+
+	if len(failed) > 0 {
+		return results, &SyncError{Errors: failed}
+	}
+
+	return results, nil
+}
+
+// syncTexts adds the translations of the texts to the translations of a
+// target language, by key, and returns the errors of the keys that failed.
+func (c *Client) syncTexts(ctx context.Context, translations map[string]string, keys, texts []string, source, target string) map[string]error {
+	if len(texts) == 0 {
+		return nil
+	}
+
+	translated, err := c.translateTexts(ctx, texts, source, target)
+	if err == nil {
+		for i, key := range keys {
+			translations[key] = translated[i]
+		}
+
+		return nil
+	}
+
+	errs := make(map[string]error)
+
+	// Only an error about the texts, not the request as a whole, is worth
+	// sending the keys one at a time.
+	var apiErr *APIError
+	if len(texts) == 1 || !errors.As(err, &apiErr) || requestError(err) {
+		for _, key := range keys {
+			errs[key] = err
+		}
+
+		return errs
+	}
+
+	for i, key := range keys {
+		if ctx.Err() != nil {
+			errs[key] = ctx.Err()
+			continue
+		}
+
+		translated, err := c.translateTexts(ctx, texts[i:i+1], source, target)
+		if err != nil {
+			errs[key] = err
+			continue
+		}
+
+		translations[key] = translated[0]
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+// requestError reports whether an error applies to any text of the request,
+// such as an invalid language or API key.
+func requestError(err error) bool {
+	return errors.Is(err, ErrInvalidSource) ||
+		errors.Is(err, ErrInvalidTarget) ||
+		errors.Is(err, ErrInvalidFormat) ||
+		errors.Is(err, ErrInvalidAPIKey) ||
+		errors.Is(err, ErrUnsupportedLanguagePair)
+}
@@ -0,0 +1,136 @@
+package libretranslate
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+)
+
+// WithChunkSize sets the maximum number of characters TranslateLong sends per
+// request. By default, the character limit of the instance is used, as
+// reported by its settings.
+func WithChunkSize(chars int) Option {
+	return func(c *Client) {
+		c.chunkSize = chars
+	}
+}
+
+// WithChunkOverlap makes TranslateLong send the last sentences of the
+// previous chunk along with each chunk, as context. The context is translated
+// and then trimmed from the output, which improves the coherence of the
+// terminology and references across chunk boundaries.
+//
+// Each sentence of overlap is translated twice, so it increases the number of
+// characters processed, and it reduces the room left for new text in each
+// request. One or two sentences are usually enough.
+func WithChunkOverlap(sentences int) Option {
+	return func(c *Client) {
+		c.chunkOverlap = sentences
+	}
+}
+
+// overlapSeparator separates the context from the chunk in a request. The
+// server keeps paragraph breaks, so the translated context ends at the first one.
+const overlapSeparator = "\n\n"
+
+// TranslateLong translates a text exceeding the character limit of the
+// instance, by splitting it into chunks of whole sentences translated one
+// after the other. The whitespace between the chunks is preserved.
+func (c *Client) TranslateLong(text, source, target string) (string, error) {
+	return c.TranslateLongContext(context.Background(), text, source, target)
+}
+
+// TranslateLongContext is like TranslateLong but uses the given context for the requests.
+func (c *Client) TranslateLongContext(ctx context.Context, text, source, target string) (string, error) {
+	limit := c.chunkSize
+	if limit <= 0 {
+		settings, err := c.settings(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		limit = settings.CharLimit
+	}
+
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return c.TranslateContext(ctx, text, source, target)
+	}
+
+	sentences := splitSentencesKeepSpace(text)
+
+	var out strings.Builder
+
+	for start := 0; start < len(sentences); {
+		overlap := c.overlapContext(sentences[:start])
+		room := limit
+		if overlap != "" {
+			room -= utf8.RuneCountInString(overlap) + len(overlapSeparator)
+		}
+
+		end := start + 1
+		size := utf8.RuneCountInString(sentences[start])
+
+		for end < len(sentences) && size+utf8.RuneCountInString(sentences[end]) <= room {
+			size += utf8.RuneCountInString(sentences[end])
+			end++
+		}
+
+		translated, err := c.translateChunk(ctx, overlap, strings.Join(sentences[start:end], ""), source, target)
+		if err != nil {
+			return "", err
+		}
+
+		out.WriteString(translated)
+		start = end
+	}
+
+	return out.String(), nil
+}
+
+// overlapContext returns the last sentences of the previous chunks to send as
+// context, joined on a single line.
+func (c *Client) overlapContext(previous []string) string {
+	if c.chunkOverlap <= 0 || len(previous) == 0 {
+		return ""
+	}
+
+	var overlap []string
+
+	for i := len(previous) - 1; i >= 0 && len(overlap) < c.chunkOverlap; i-- {
+		if sentence := strings.Join(strings.Fields(previous[i]), " "); sentence != "" {
+			overlap = append([]string{sentence}, overlap...)
+		}
+	}
+
+	return strings.Join(overlap, " ")
+}
+
+// translateChunk translates a chunk preceded by the given overlap, keeping the
+// whitespace surrounding the chunk. If the translated context cannot be told
+// apart from the chunk, the chunk is translated again without context.
+func (c *Client) translateChunk(ctx context.Context, overlap, chunk, source, target string) (string, error) {
+	core := strings.TrimSpace(chunk)
+	if core == "" {
+		return chunk, nil
+	}
+
+	lead, trail := surroundingSpace(chunk)
+
+	if overlap != "" {
+		translated, err := c.TranslateContext(ctx, overlap+overlapSeparator+core, source, target)
+		if err != nil {
+			return "", err
+		}
+
+		if _, after, ok := strings.Cut(translated, overlapSeparator); ok {
+			return lead + strings.TrimSpace(after) + trail, nil
+		}
+	}
+
+	translated, err := c.TranslateContext(ctx, core, source, target)
+	if err != nil {
+		return "", err
+	}
+
+	return lead + translated + trail, nil
+}
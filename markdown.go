@@ -0,0 +1,249 @@
+package libretranslate
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+var (
+	// mdFencePattern matches the opening of a fenced code block, after any
+	// indentation and block quote markers.
+	mdFencePattern = regexp.MustCompile("^[ \t>]*(`{3,}|~{3,})")
+	// mdVerbatimPattern matches the lines kept as they are: thematic breaks,
+	// setext heading underlines, table delimiter rows, link reference
+	// definitions and HTML blocks.
+	mdVerbatimPattern = regexp.MustCompile(`^[ \t>]*(?:(?:[-*_][ \t]*){3,}|=+|\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?|\[[^\]]+\]:[ \t]*\S.*|<.*)$`)
+	// mdPrefixPattern matches the markers starting a line: indentation, block
+	// quotes, list items (with task boxes) and ATX headings.
+	mdPrefixPattern = regexp.MustCompile(`^[ \t]*(?:>[ \t]?)*[ \t]*(?:(?:[-*+]|\d{1,9}[.)])[ \t]+(?:\[[ xX]\][ \t]+)?)?(?:#{1,6}[ \t]+)?`)
+	// mdListPattern matches a list item marker after the block quote markers.
+	mdListPattern = regexp.MustCompile(`^[ \t]*(?:>[ \t]?)*[ \t]*(?:[-*+]|\d{1,9}[.)])[ \t]`)
+	// mdHeadingSuffixPattern matches the optional closing sequence of an ATX heading.
+	mdHeadingSuffixPattern = regexp.MustCompile(`[ \t]+#+[ \t]*$`)
+	// mdInlinePattern matches the inline elements that are not prose: code
+	// spans, link destinations and references, footnote references,
+	// autolinks, inline HTML and bare urls.
+	mdInlinePattern = regexp.MustCompile("``.*?``|`[^`]*`|\\]\\([^)]*\\)|\\]\\[[^\\]]*\\]|\\[\\^[^\\]]+\\]|<[^>\\s][^>]*>|https?://[^\\s)>]+")
+)
+
+// MarkdownResult represents the result of a Markdown translation.
+type MarkdownResult struct {
+	// Translated document
+	Text string
+	// Segments left untranslated because they could not be translated
+	// safely: HTML blocks, and texts whose markup was not preserved by the
+	// server
+	Skipped []string
+}
+
+// TranslateMarkdown translates the prose of a Markdown document, keeping its
+// structure: code blocks, inline code, urls, link destinations and HTML are
+// not translated, and the markers of headings, lists, block quotes and tables
+// are preserved. The lines of a paragraph are translated together and joined
+// on a single line.
+func (c *Client) TranslateMarkdown(md, source, target string) (string, error) {
+	return c.TranslateMarkdownContext(context.Background(), md, source, target)
+}
+
+// TranslateMarkdownContext is like TranslateMarkdown but uses the given context for the request.
+func (c *Client) TranslateMarkdownContext(ctx context.Context, md, source, target string) (string, error) {
+	result, err := c.TranslateMarkdownDetailed(ctx, md, source, target)
+	if err != nil {
+		return "", err
+	}
+
+	return result.Text, nil
+}
+
+// TranslateMarkdownDetailed is like TranslateMarkdownContext but also reports
+// the segments that were left untranslated.
+func (c *Client) TranslateMarkdownDetailed(ctx context.Context, md, source, target string) (MarkdownResult, error) {
+	pieces, skipped := splitMarkdown(md)
+
+	var (
+		indexes   []int
+		protected []protectedText
+		masked    []string
+	)
+
+	for i, piece := range pieces {
+		if !piece.translate {
+			continue
+		}
+
+		p := protectMarkdown(piece.text)
+		indexes = append(indexes, i)
+		protected = append(protected, p)
+		masked = append(masked, p.text)
+	}
+
+	if len(masked) > 0 {
+		translations, err := c.translateTexts(ctx, masked, source, target)
+		if err != nil {
+			return MarkdownResult{}, err
+		}
+
+		for j, i := range indexes {
+			translated, warnings := protected[j].restore(translations[j])
+			if len(warnings) > 0 {
+				skipped = append(skipped, pieces[i].text)
+				continue
+			}
+
+			pieces[i].text = translated
+		}
+	}
+
+	var b strings.Builder
+	for _, piece := range pieces {
+		b.WriteString(piece.text)
+	}
+
+	return MarkdownResult{Text: b.String(), Skipped: skipped}, nil
+}
+
+// splitMarkdown splits a Markdown document into the pieces of prose to
+// translate and the markup around them, and returns the HTML blocks, which
+// are not translated.
+func splitMarkdown(md string) ([]textPiece, []string) {
+	var (
+		pieces  []textPiece
+		skipped []string
+		// fence is the marker of the fenced code block the line is in, if any
+		fence string
+		// paragraph is the index of the piece holding the text of the
+		// paragraph the next line may continue, or -1
+		paragraph = -1
+		blank     = true
+		code      bool
+		list      bool
+	)
+
+	for _, line := range strings.SplitAfter(md, "\n") {
+		if line == "" {
+			continue
+		}
+
+		content := strings.TrimRight(line, "\r\n")
+		end := line[len(content):]
+		trimmed := strings.TrimSpace(content)
+		indented := strings.HasPrefix(content, "    ") || strings.HasPrefix(content, "\t")
+
+		switch {
+		case fence != "":
+			if strings.HasPrefix(strings.TrimLeft(content, " \t>"), fence) && strings.Trim(trimmed, " \t>"+fence[:1]) == "" {
+				fence = ""
+			}
+		case trimmed == "":
+			paragraph, blank = -1, true
+		case mdFencePattern.MatchString(content):
+			fence = mdFencePattern.FindStringSubmatch(content)[1]
+			paragraph = -1
+		case indented && !list && (blank || code):
+			code = true
+		case mdVerbatimPattern.MatchString(content):
+			if strings.HasPrefix(strings.TrimLeft(content, " \t>"), "<") {
+				skipped = append(skipped, trimmed)
+			}
+
+			paragraph = -1
+		case strings.HasPrefix(strings.TrimLeft(content, " \t>"), "|"):
+			pieces = appendTableRow(pieces, content)
+			pieces = append(pieces, textPiece{text: end})
+			paragraph, blank, code = -1, false, false
+
+			continue
+		default:
+			prefix := mdPrefixPattern.FindString(content)
+			text := content[len(prefix):]
+			marker := mdListPattern.MatchString(prefix) || strings.Contains(prefix, "#")
+
+			if mdListPattern.MatchString(prefix) {
+				list = true
+			} else if blank && !indented {
+				list = false
+			}
+
+			if paragraph >= 0 && !marker {
+				// Continue the paragraph on its first line.
+				pieces = pieces[:len(pieces)-1]
+				pieces[paragraph].text += " " + strings.TrimSpace(text)
+				pieces = append(pieces, textPiece{text: end})
+				blank, code = false, false
+
+				continue
+			}
+
+			suffix := ""
+			if strings.Contains(prefix, "#") {
+				suffix = mdHeadingSuffixPattern.FindString(text)
+				text = text[:len(text)-len(suffix)]
+			}
+
+			pieces = append(pieces, textPiece{text: prefix})
+			pieces = appendTranslatable(pieces, text)
+			paragraph = len(pieces) - 1
+			if !pieces[paragraph].translate || strings.Contains(prefix, "#") {
+				// Headings hold a single line, and a line ending with
+				// whitespace (such as a hard line break) ends its paragraph.
+				paragraph = -1
+			}
+
+			pieces = append(pieces, textPiece{text: suffix + end})
+			blank, code = false, false
+
+			continue
+		}
+
+		pieces = append(pieces, textPiece{text: line})
+		if trimmed != "" {
+			blank = false
+		}
+
+		if !indented {
+			code = false
+		}
+	}
+
+	return pieces, skipped
+}
+
+// appendTableRow appends the cells of a table row to translate, keeping the
+// pipes and the escaped pipes of the cells. The row must contain a pipe.
+func appendTableRow(pieces []textPiece, row string) []textPiece {
+	start := 0
+
+	for i := 0; i < len(row); i++ {
+		switch row[i] {
+		case '\\':
+			i++
+		case '|':
+			if start == 0 {
+				// Keep the indentation and block quote markers.
+				pieces = append(pieces, textPiece{text: row[:i+1]})
+			} else {
+				pieces = appendTranslatable(pieces, row[start:i])
+				pieces = append(pieces, textPiece{text: "|"})
+			}
+
+			start = i + 1
+		}
+	}
+
+	return appendTranslatable(pieces, row[start:])
+}
+
+// protectMarkdown replaces the inline elements of a Markdown text that are not
+// prose with sentinel tokens, numbered after the tokens already in the text.
+func protectMarkdown(text string) protectedText {
+	protected := protectedText{offset: sentinelOffset(text)}
+
+	protected.text = mdInlinePattern.ReplaceAllStringFunc(text, func(element string) string {
+		protected.placeholders = append(protected.placeholders, element)
+
+		return sentinel(protected.offset + len(protected.placeholders) - 1)
+	})
+
+	return protected
+}
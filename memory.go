@@ -0,0 +1,55 @@
+package libretranslate
+
+import "context"
+
+// TMStore is a translation memory: a store of approved translations used
+// instead of the API, which keeps the terminology consistent.
+type TMStore interface {
+	// Lookup returns the stored translation of a text, if any.
+	Lookup(ctx context.Context, query, source, target string) (translation string, ok bool, err error)
+	// Store records the translation of a text returned by the API.
+	Store(ctx context.Context, query, source, target, translation string) error
+}
+
+// WithTranslationMemory makes the client look up every text in the given
+// translation memory before calling the API, and store the translations
+// returned by the API in it.
+//
+// The memory is checked after the response cache. Its errors do not fail the
+// translations: they are reported in the warnings of the results.
+func WithTranslationMemory(tm TMStore) Option {
+	return func(c *Client) {
+		c.memory = tm
+	}
+}
+
+// recall looks up a text in the translation memory. If the lookup fails, the
+// returned result is not ok and only holds a warning.
+func (c *Client) recall(ctx context.Context, query, source, target string) (TranslateResult, bool) {
+	if c.memory == nil {
+		return TranslateResult{}, false
+	}
+
+	translation, ok, err := c.memory.Lookup(ctx, query, source, target)
+	if err != nil {
+		return TranslateResult{Warnings: []string{"translation memory lookup failed: " + err.Error()}}, false
+	}
+
+	if !ok {
+		return TranslateResult{}, false
+	}
+
+	return TranslateResult{Source: query, TranslatedText: translation}, true
+}
+
+// memorize stores a translation returned by the API in the translation
+// memory, adding a warning to the result if it fails.
+func (c *Client) memorize(ctx context.Context, result *TranslateResult, source, target string) {
+	if c.memory == nil {
+		return
+	}
+
+	if err := c.memory.Store(ctx, result.Source, source, target, result.TranslatedText); err != nil {
+		result.Warnings = append(result.Warnings, "translation memory store failed: "+err.Error())
+	}
+}
@@ -0,0 +1,97 @@
+package libretranslate
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Handler sends a request to the API and returns its response. Every attempt
+// of a request made by the client goes through a chain of handlers.
+type Handler func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler, for instance to add headers, collect metrics or
+// serve responses from a cache.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware adds middleware to the chain of the client, after (inside)
+// the middleware already in it. By default the chain only holds
+// CheckResponseErrors, so the added middleware sees the raw responses of
+// the API, including error responses.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware[:len(c.middleware):len(c.middleware)], middleware...)
+	}
+}
+
+// WithMiddlewareChain replaces the chain of the client, which allows placing
+// the built-in CheckResponseErrors anywhere in it. The first middleware is the
+// outermost one. Responses that are not successful and reach the client
+// unchecked are still turned into errors.
+func WithMiddlewareChain(middleware ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = middleware
+	}
+}
+
+// CheckResponseErrors is the middleware turning the error responses of the
+// API into *APIError errors, which the client uses to decide whether to retry
+// a request. It is part of the chain of every client by default.
+func CheckResponseErrors(next Handler) Handler {
+	return func(req *http.Request) (*http.Response, error) {
+		res, err := next(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := checkForResponseErrors(res, requestParams(req.Context())); err != nil {
+			return nil, err
+		}
+
+		return res, nil
+	}
+}
+
+// handler returns the chain of the client wrapped around send.
+func (c *Client) handler() Handler {
+	handler := c.send
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		handler = c.middleware[i](handler)
+	}
+
+	return handler
+}
+
+// send is the innermost handler: it sends a request with the http.Client of
+// the client and limits the size of its response.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, sendError(req.Context(), err)
+	}
+
+	res.Body = c.limitBody(res.Body)
+
+	return res, nil
+}
+
+// requestParamsKey is the context key of the parameters of a request, used to
+// classify its errors.
+type requestParamsKey struct{}
+
+// requestParams returns the parameters of the request made with the given context.
+func requestParams(ctx context.Context) url.Values {
+	params, _ := ctx.Value(requestParamsKey{}).(url.Values)
+
+	return params
+}
+
+// requestHeaderKey is the context key of the headers added to a request by
+// the client itself, such as the conditions of a conditional request.
+type requestHeaderKey struct{}
+
+// withRequestHeader returns a copy of the context adding the given headers to
+// the requests made with it.
+func withRequestHeader(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, requestHeaderKey{}, header)
+}
@@ -0,0 +1,207 @@
+package libretranslate
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithToken sets the API key sent with the requests. It is mostly useful with
+// Clone, since the constructors take the token as an argument.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithBaseURL sets the base url of the API. It is mostly useful with Clone,
+// since the constructors take the base url as an argument.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseUrl = baseURL
+	}
+}
+
+// WithAPIBasePath sets the path the API is mounted at on the server, such as
+// "/api", when it is not in the base url. The path is joined between the path
+// of the base url (or of each backend) and the endpoints, ignoring the extra
+// or missing slashes, so "api", "/api" and "/api/" are the same.
+func WithAPIBasePath(basePath string) Option {
+	return func(c *Client) {
+		c.apiBasePath = basePath
+	}
+}
+
+// WithContentType sets the Content-Type header sent with POST requests.
+//
+// The request body is encoded according to the media type: "application/json"
+// (or any "+json" type) sends a JSON object, anything else sends form-encoded
+// parameters. Parameters such as charset are sent as given. The default is
+// "application/x-www-form-urlencoded".
+func WithContentType(contentType string) Option {
+	return func(c *Client) {
+		c.contentType = contentType
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with the requests, instead of
+// the default one of the http package.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithMethodOverride makes the client send GET requests (such as the ones
+// retrieving the languages and the settings) as POST requests carrying an
+// "X-HTTP-Method-Override: GET" header, for networks whose proxies reject
+// GET requests with a body. The server, or a proxy in front of it, must
+// support the header.
+func WithMethodOverride(enabled bool) Option {
+	return func(c *Client) {
+		c.methodOverride = enabled
+	}
+}
+
+// WithoutSameLanguagePassthrough makes the client send translation requests
+// even when the source and target languages are the same. By default, such
+// requests are skipped and the text is returned unchanged.
+func WithoutSameLanguagePassthrough() Option {
+	return func(c *Client) {
+		c.strictLanguagePair = true
+	}
+}
+
+// WithFallbackToSource makes Translate and TranslateContext return the
+// original text instead of an error when the translation fails, which keeps
+// user interfaces working during outages. TranslateDetailed still returns the
+// error, so it can be logged.
+func WithFallbackToSource() Option {
+	return func(c *Client) {
+		c.fallbackToSource = true
+	}
+}
+
+// WithWhitespacePreservation makes the client give translations the leading
+// and trailing whitespace (including line breaks) of the original text, which
+// the server usually trims. It suits fragments concatenated in templates.
+func WithWhitespacePreservation() Option {
+	return func(c *Client) {
+		c.preserveWhitespace = true
+	}
+}
+
+// WithRequestSigner sets a function called on every request once it is
+// complete, for gateways requiring a signature header computed over the body
+// and a shared secret. The signer runs again for each attempt, with the body
+// actually sent (compressed if WithRequestCompression applies), which it can
+// read from req.Body or req.GetBody. A signer error fails the request.
+func WithRequestSigner(signer func(*http.Request) error) Option {
+	return func(c *Client) {
+		c.signer = signer
+	}
+}
+
+// WithErrorOnEmptyResult makes the client fail with ErrEmptyTranslation when
+// the server returns an empty translation for a text that is not empty, which
+// misconfigured instances do, instead of returning the empty translation.
+// Responses with no content (204) to translation requests then fail with
+// ErrEmptyTranslation too; without it, they fail with a plain *APIError.
+func WithErrorOnEmptyResult() Option {
+	return func(c *Client) {
+		c.errorOnEmpty = true
+	}
+}
+
+// WithStrictDecoding makes the client reject API responses containing fields
+// it does not know about, which helps detecting API changes in tests. It is
+// off by default so that new server fields do not break the client.
+func WithStrictDecoding() Option {
+	return func(c *Client) {
+		c.strictDecoding = true
+	}
+}
+
+// WithErrorContext makes the messages of the *APIError errors report the
+// source and target languages of the failed request, and the beginning of its
+// text, which helps debugging bulk jobs. The API key is never reported. These
+// values are always available in the fields of the error.
+func WithErrorContext() Option {
+	return func(c *Client) {
+		c.errorContext = true
+	}
+}
+
+// CallOption configures a single translation request.
+type CallOption func(*callOptions)
+
+// callOptions holds the settings of a single translation request.
+type callOptions struct {
+	format       string
+	alternatives int
+	apiKey       string
+	engine       string
+}
+
+// newCallOptions applies the given options over the defaults.
+func newCallOptions(opts []CallOption) callOptions {
+	var o callOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// setParams adds the non-default settings to the request parameters.
+func (o callOptions) setParams(params url.Values) {
+	if o.format != "" {
+		params.Set("format", o.format)
+	}
+
+	if o.alternatives > 0 {
+		params.Set("alternatives", strconv.Itoa(o.alternatives))
+	}
+
+	if o.engine != "" {
+		params.Set("engine", o.engine)
+	}
+}
+
+// WithFormat sets the format of the text to translate, "text" (the server
+// default) or "html".
+func WithFormat(format string) CallOption {
+	return func(o *callOptions) {
+		o.format = format
+	}
+}
+
+// WithAlternatives requests up to n alternative translations, returned in
+// TranslateResult.Alternatives.
+func WithAlternatives(n int) CallOption {
+	return func(o *callOptions) {
+		o.alternatives = n
+	}
+}
+
+// WithAPIKey sets the API key of a single request, overriding the token of
+// the client. A key stored in the context with ContextWithAPIKey takes
+// precedence over it.
+func WithAPIKey(key string) CallOption {
+	return func(o *callOptions) {
+		o.apiKey = key
+	}
+}
+
+// WithEngine selects the translation engine or model of a request, on
+// instances offering several of them. Instances that do not support it ignore
+// the parameter. The engine used, if reported by the server, is returned in
+// TranslateResult.Engine.
+func WithEngine(name string) CallOption {
+	return func(o *callOptions) {
+		o.engine = name
+	}
+}
@@ -0,0 +1,39 @@
+package libretranslate
+
+import "testing"
+
+func TestEndpointURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseURL  string
+		basePath string
+		endpoint string
+		want     string
+	}{
+		{"no base path", "http://localhost:5000", "", "/translate", "http://localhost:5000/translate"},
+		{"trailing slash in the base url", "http://localhost:5000/", "", "/translate", "http://localhost:5000/translate"},
+		{"relative base path", "http://localhost:5000", "api", "/translate", "http://localhost:5000/api/translate"},
+		{"absolute base path", "http://localhost:5000", "/api", "/translate", "http://localhost:5000/api/translate"},
+		{"base path with slashes", "http://localhost:5000/", "/api/", "/translate", "http://localhost:5000/api/translate"},
+		{"base path in the base url", "http://localhost:5000/api", "", "/translate", "http://localhost:5000/api/translate"},
+		{"base path in the base url with a trailing slash", "http://localhost:5000/api/", "", "translate", "http://localhost:5000/api/translate"},
+		{"base path after the path of the base url", "http://localhost:5000/libre/", "api/", "/translate/", "http://localhost:5000/libre/api/translate"},
+		{"nested base path", "http://localhost:5000", "/api/v1", "/languages", "http://localhost:5000/api/v1/languages"},
+		{"query string of the base url", "http://localhost:5000/api?tenant=a", "", "/translate", "http://localhost:5000/api/translate?tenant=a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClientWithBaseURL(tt.baseURL, "key", WithAPIBasePath(tt.basePath))
+
+			got, err := c.EndpointURL(tt.endpoint)
+			if err != nil {
+				t.Fatalf("EndpointURL: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
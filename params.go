@@ -0,0 +1,113 @@
+package libretranslate
+
+import (
+	"encoding/json"
+	"maps"
+	"net/url"
+	"strings"
+)
+
+// ArrayEncoding is the way the texts of a batch request are encoded in the
+// request body.
+type ArrayEncoding int
+
+const (
+	// ArrayJSON sends a JSON body with the texts in an array:
+	// {"q": ["a", "b"]}. This is what LibreTranslate expects, and the default.
+	ArrayJSON ArrayEncoding = iota
+	// ArrayRepeated sends a form-encoded body repeating the parameter:
+	// q=a&q=b.
+	ArrayRepeated
+	// ArrayBrackets sends a form-encoded body repeating the parameter with
+	// brackets: q[]=a&q[]=b.
+	ArrayBrackets
+	// ArrayJSONString sends a form-encoded body with the texts in a JSON
+	// array: q=["a","b"].
+	ArrayJSONString
+)
+
+// WithArrayEncoding sets how the texts of batch requests are encoded, for
+// servers or proxies that only accept form-encoded bodies.
+//
+// The form encodings use the Content-Type set with WithContentType, or the
+// default one if it is a JSON type. Requests with a single text are not
+// affected.
+func WithArrayEncoding(encoding ArrayEncoding) Option {
+	return func(c *Client) {
+		c.arrayEncoding = encoding
+	}
+}
+
+// encodeArrays returns the parameters and the Content-Type of a request
+// carrying several texts, according to the array encoding of the client.
+// The given parameters are not modified.
+func (c *Client) encodeArrays(params url.Values, contentType string) (url.Values, string, error) {
+	if len(params["q"]) <= 1 {
+		return params, contentType, nil
+	}
+
+	if c.arrayEncoding == ArrayJSON {
+		if !isJSONContentType(contentType) {
+			contentType = "application/json"
+		}
+
+		return params, contentType, nil
+	}
+
+	if isJSONContentType(contentType) {
+		contentType = DefaultContentType
+	}
+
+	switch c.arrayEncoding {
+	case ArrayBrackets:
+		params = maps.Clone(params)
+		params["q[]"] = params["q"]
+		delete(params, "q")
+	case ArrayJSONString:
+		array, err := json.Marshal(params["q"])
+		if err != nil {
+			return nil, "", err
+		}
+
+		params = maps.Clone(params)
+		params["q"] = []string{string(array)}
+	}
+
+	return params, contentType, nil
+}
+
+// WithParamNames renames the parameters sent to the API, for deployments
+// that do not use the standard names. The map goes from the standard names
+// ("q", "source", "target", "format", "alternatives", "api_key", ...) to the
+// ones of the deployment, for instance {"q": "text"}. Parameters missing from
+// the map keep their name.
+//
+// The brackets added by ArrayBrackets are kept after the new name.
+func WithParamNames(names map[string]string) Option {
+	return func(c *Client) {
+		c.paramNames = maps.Clone(names)
+	}
+}
+
+// renameParams returns the parameters with the names set by WithParamNames.
+// The given parameters are not modified.
+func (c *Client) renameParams(params url.Values) url.Values {
+	if len(c.paramNames) == 0 {
+		return params
+	}
+
+	renamed := make(url.Values, len(params))
+	for name, values := range params {
+		base, brackets := strings.CutSuffix(name, "[]")
+		if newName, ok := c.paramNames[base]; ok {
+			name = newName
+			if brackets {
+				name += "[]"
+			}
+		}
+
+		renamed[name] = values
+	}
+
+	return renamed
+}
@@ -0,0 +1,130 @@
+package libretranslate
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// requestBody builds a POST request to /translate and returns its url, body
+// and Content-Type.
+func requestBody(t *testing.T, c *Client, params url.Values) (string, string, string) {
+	t.Helper()
+
+	req, err := c.buildRequest(context.Background(), nil, http.MethodPost, "/translate", params)
+	if err != nil {
+		t.Fatalf("buildRequest: %v", err)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("reading the request body: %v", err)
+	}
+
+	return req.URL.String(), string(body), req.Header.Get("Content-Type")
+}
+
+func TestEncodeArrays(t *testing.T) {
+	single := url.Values{"q": {"a b"}, "target": {"es"}}
+	batch := url.Values{"q": {"a b", "c"}, "target": {"es"}}
+
+	tests := []struct {
+		name            string
+		opts            []Option
+		params          url.Values
+		wantBody        string
+		wantContentType string
+	}{
+		{"json single", nil, single, "q=a+b&target=es", DefaultContentType},
+		{"json batch", nil, batch, `{"q":["a b","c"],"target":"es"}`, "application/json"},
+		{"json batch with a JSON type", []Option{WithContentType("application/vnd.api+json")}, batch, `{"q":["a b","c"],"target":"es"}`, "application/vnd.api+json"},
+		{"repeated single", []Option{WithArrayEncoding(ArrayRepeated)}, single, "q=a+b&target=es", DefaultContentType},
+		{"repeated batch", []Option{WithArrayEncoding(ArrayRepeated)}, batch, "q=a+b&q=c&target=es", DefaultContentType},
+		{"repeated batch with a JSON type", []Option{WithArrayEncoding(ArrayRepeated), WithContentType("application/json")}, batch, "q=a+b&q=c&target=es", DefaultContentType},
+		{"brackets single", []Option{WithArrayEncoding(ArrayBrackets)}, single, "q=a+b&target=es", DefaultContentType},
+		{"brackets batch", []Option{WithArrayEncoding(ArrayBrackets)}, batch, "q%5B%5D=a+b&q%5B%5D=c&target=es", DefaultContentType},
+		{"JSON string single", []Option{WithArrayEncoding(ArrayJSONString)}, single, "q=a+b&target=es", DefaultContentType},
+		{"JSON string batch", []Option{WithArrayEncoding(ArrayJSONString)}, batch, "q=%5B%22a+b%22%2C%22c%22%5D&target=es", DefaultContentType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClientWithBaseURL("http://localhost:5000", "", tt.opts...)
+
+			before := tt.params.Encode()
+
+			_, body, contentType := requestBody(t, c, tt.params)
+
+			if body != tt.wantBody {
+				t.Errorf("got body %q, want %q", body, tt.wantBody)
+			}
+
+			if contentType != tt.wantContentType {
+				t.Errorf("got Content-Type %q, want %q", contentType, tt.wantContentType)
+			}
+
+			if after := tt.params.Encode(); after != before {
+				t.Errorf("the parameters were modified: got %q, want %q", after, before)
+			}
+		})
+	}
+}
+
+func TestRenameParams(t *testing.T) {
+	names := map[string]string{"q": "text", "api_key": "key"}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		params   url.Values
+		wantURL  string
+		wantBody string
+	}{
+		{
+			"body",
+			nil,
+			url.Values{"q": {"a"}, "target": {"es"}, "api_key": {"secret"}},
+			"http://localhost:5000/translate",
+			"key=secret&target=es&text=a",
+		},
+		{
+			"query string",
+			[]Option{WithAuthPlacement(http.MethodPost, AuthQuery)},
+			url.Values{"q": {"a"}, "target": {"es"}, "api_key": {"secret"}},
+			"http://localhost:5000/translate?key=secret",
+			"target=es&text=a",
+		},
+		{
+			"brackets",
+			[]Option{WithArrayEncoding(ArrayBrackets)},
+			url.Values{"q": {"a", "b"}, "target": {"es"}},
+			"http://localhost:5000/translate",
+			"target=es&text%5B%5D=a&text%5B%5D=b",
+		},
+		{
+			"JSON batch",
+			nil,
+			url.Values{"q": {"a", "b"}, "target": {"es"}},
+			"http://localhost:5000/translate",
+			`{"target":"es","text":["a","b"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClientWithBaseURL("http://localhost:5000", "", append(tt.opts, WithParamNames(names))...)
+
+			uri, body, _ := requestBody(t, c, tt.params)
+
+			if uri != tt.wantURL {
+				t.Errorf("got url %q, want %q", uri, tt.wantURL)
+			}
+
+			if body != tt.wantBody {
+				t.Errorf("got body %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
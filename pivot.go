@@ -0,0 +1,70 @@
+package libretranslate
+
+import (
+	"context"
+	"slices"
+)
+
+// WithPivotLanguage makes the client translate through the given language
+// (usually "en") when the server does not support translating directly from
+// the source language into the target language. Both legs are checked against
+// the supported languages before they are sent, and the result has Pivoted set.
+//
+// Pivoting doubles the number of characters processed, and errors can add up
+// across the two legs.
+func WithPivotLanguage(code string) Option {
+	return func(c *Client) {
+		c.pivotLanguage = code
+	}
+}
+
+// translatePivoted translates a text through the pivot language after the
+// direct translation failed with the given error, which is returned if either
+// leg is not supported.
+func (c *Client) translatePivoted(ctx context.Context, query, source, target string, opts callOptions, directErr error) (TranslateResult, error) {
+	pivot := c.pivotLanguage
+	if source == pivot || target == pivot {
+		return TranslateResult{}, directErr
+	}
+
+	languages, err := c.languages(ctx)
+	if err != nil {
+		return TranslateResult{}, directErr
+	}
+
+	if source != "auto" && !supportsPair(languages, source, pivot) || !supportsPair(languages, pivot, target) {
+		return TranslateResult{}, directErr
+	}
+
+	// Only the second leg gives alternatives in the target language.
+	firstOpts := opts
+	firstOpts.alternatives = 0
+
+	first, err := c.sendTranslate(ctx, query, source, pivot, firstOpts)
+	if err != nil {
+		return TranslateResult{}, err
+	}
+
+	result, err := c.sendTranslate(ctx, first.TranslatedText, pivot, target, opts)
+	if err != nil {
+		return TranslateResult{}, err
+	}
+
+	result.DetectedLanguage = first.DetectedLanguage
+	result.DetectionAlternatives = first.DetectionAlternatives
+	result.Pivoted = true
+
+	return result, nil
+}
+
+// supportsPair reports whether the given languages include a translation from
+// source into target.
+func supportsPair(languages []Language, source, target string) bool {
+	for _, language := range languages {
+		if language.Code == source {
+			return slices.Contains(language.Targets, target)
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,292 @@
+package libretranslate
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PlaceholderStyle selects the kinds of placeholders protected from translation.
+// Styles can be combined with the | operator.
+type PlaceholderStyle int
+
+const (
+	// PlaceholderPrintf matches printf-style verbs such as %s, %d, %.2f, %1$s and %(name)s.
+	PlaceholderPrintf PlaceholderStyle = 1 << iota
+	// PlaceholderBraces matches positional and named braces such as {}, {0} and {name}.
+	PlaceholderBraces
+	// PlaceholderICU matches ICU message arguments such as {count, number}.
+	// Arguments with nested messages, such as plural or select, are protected
+	// as a whole.
+	PlaceholderICU
+
+	// PlaceholderAll matches all the supported styles.
+	PlaceholderAll = PlaceholderPrintf | PlaceholderBraces | PlaceholderICU
+)
+
+var (
+	printfPattern   = regexp.MustCompile(`%(?:\(\w+\)|\d+\$)?[-+#0]*(?:\d+|\*)?(?:\.\d+)?[sdifFeEgGxXoqvtcbpu%]`)
+	bracesPattern   = regexp.MustCompile(`\{\w*\}`)
+	icuStartPattern = regexp.MustCompile(`\{\s*\w+\s*,`)
+
+	// sentinelPattern matches the tokens that replace placeholders. Spaces
+	// inserted by the server inside the token are tolerated.
+	sentinelPattern = regexp.MustCompile(`\[\[\s*(\d+)\s*\]\]`)
+)
+
+// WithPlaceholderProtection makes the client replace the placeholders of the
+// given styles with sentinel tokens before sending a text, and restore them in
+// the translation. This keeps format strings of message catalogs usable.
+//
+// If the server drops, duplicates or reorders a token, the translation is
+// still returned and the problem is reported in TranslateResult.Warnings.
+func WithPlaceholderProtection(style PlaceholderStyle) Option {
+	return func(c *Client) {
+		c.placeholders = style
+	}
+}
+
+// WithDoNotTranslate makes the client keep the given terms, such as names of
+// people, places or brands, untranslated: they are replaced with sentinel
+// tokens before a text is sent and restored in the translation, like the
+// placeholders of WithPlaceholderProtection.
+//
+// Terms are matched case-sensitively, so that "Apple" is protected but not
+// "apple", and only as whole words: "Go" does not match in "Google". When
+// terms overlap, the longest one wins. If the server drops or duplicates a
+// term, it is reported in TranslateResult.Warnings.
+func WithDoNotTranslate(terms []string) Option {
+	return func(c *Client) {
+		var quoted []string
+		for _, term := range terms {
+			if term != "" {
+				quoted = append(quoted, regexp.QuoteMeta(term))
+			}
+		}
+
+		if len(quoted) == 0 {
+			c.doNotTranslate = nil
+			return
+		}
+
+		// Longer terms first, so the leftmost match is the longest one.
+		sort.SliceStable(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+
+		c.doNotTranslate = regexp.MustCompile(strings.Join(quoted, "|"))
+	}
+}
+
+// findTerms returns the spans of the do-not-translate terms found as whole
+// words in a text.
+func (c *Client) findTerms(text string) [][2]int {
+	if c.doNotTranslate == nil {
+		return nil
+	}
+
+	return findWholeWords(c.doNotTranslate, text)
+}
+
+// findWholeWords returns the spans of the matches of a pattern found as whole
+// words in a text.
+func findWholeWords(pattern *regexp.Regexp, text string) [][2]int {
+	var spans [][2]int
+
+	for _, loc := range pattern.FindAllStringIndex(text, -1) {
+		before, _ := utf8.DecodeLastRuneInString(text[:loc[0]])
+		after, _ := utf8.DecodeRuneInString(text[loc[1]:])
+
+		if !isWordRune(before) && !isWordRune(after) {
+			spans = append(spans, [2]int{loc[0], loc[1]})
+		}
+	}
+
+	return spans
+}
+
+// isWordRune reports whether a rune is part of a word.
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// protectedText is a text whose placeholders were replaced with sentinel tokens.
+type protectedText struct {
+	text         string
+	placeholders []string
+	// offset is the number of the first token, after the ones already in
+	// the text
+	offset int
+}
+
+// protectPlaceholders replaces the placeholders, do-not-translate terms and
+// glossary terms of a text with sentinel tokens. Glossary terms are restored
+// as their target terms. When the text already contains tokens, such as the
+// ones of TranslateMarkdown, the new tokens are numbered after them and the
+// existing ones are left for their owner to restore.
+func (c *Client) protectPlaceholders(text string) protectedText {
+	terms := c.glossary.Load()
+	if c.placeholders == 0 && c.doNotTranslate == nil && terms == nil {
+		return protectedText{text: text}
+	}
+
+	spans := findPlaceholders(text, c.placeholders)
+	spans = append(spans, c.findTerms(text)...)
+	spans = mergeSpans(append(spans, terms.find(text)...))
+	if len(spans) == 0 {
+		return protectedText{text: text}
+	}
+
+	protected := protectedText{placeholders: make([]string, 0, len(spans)), offset: sentinelOffset(text)}
+	masked := make([]byte, 0, len(text))
+	last := 0
+
+	for i, span := range spans {
+		protected.placeholders = append(protected.placeholders, terms.replacement(text[span[0]:span[1]]))
+		masked = append(masked, text[last:span[0]]...)
+		masked = append(masked, sentinel(protected.offset+i)...)
+		last = span[1]
+	}
+
+	protected.text = string(append(masked, text[last:]...))
+
+	return protected
+}
+
+// restore replaces the sentinel tokens of a translation with the original
+// placeholders and reports the tokens the server did not preserve. Tokens
+// numbered before the offset are left as they are.
+func (p protectedText) restore(translated string) (string, []string) {
+	if len(p.placeholders) == 0 {
+		return translated, nil
+	}
+
+	var order []int
+
+	counts := make([]int, len(p.placeholders))
+
+	restored := sentinelPattern.ReplaceAllStringFunc(translated, func(token string) string {
+		i, err := strconv.Atoi(sentinelPattern.FindStringSubmatch(token)[1])
+		i -= p.offset
+		if err != nil || i < 0 || i >= len(p.placeholders) {
+			return token
+		}
+
+		counts[i]++
+		order = append(order, i)
+
+		return p.placeholders[i]
+	})
+
+	var warnings []string
+
+	for i, count := range counts {
+		switch {
+		case count == 0:
+			warnings = append(warnings, fmt.Sprintf("placeholder %q was dropped from the translation", p.placeholders[i]))
+		case count > 1:
+			warnings = append(warnings, fmt.Sprintf("placeholder %q was duplicated in the translation", p.placeholders[i]))
+		}
+	}
+
+	if !slices.IsSorted(order) {
+		warnings = append(warnings, "placeholders were reordered in the translation")
+	}
+
+	return restored, warnings
+}
+
+// sentinel returns the token that replaces the placeholder with the given index.
+func sentinel(i int) string {
+	return "[[" + strconv.Itoa(i) + "]]"
+}
+
+// sentinelOffset returns the number after the largest sentinel token of a
+// text, or zero if it has none, so new tokens do not collide with it.
+func sentinelOffset(text string) int {
+	offset := 0
+
+	for _, match := range sentinelPattern.FindAllStringSubmatch(text, -1) {
+		// Huge numbers are not tokens of this package and cannot collide.
+		if i, err := strconv.Atoi(match[1]); err == nil && i < math.MaxInt32 && i >= offset {
+			offset = i + 1
+		}
+	}
+
+	return offset
+}
+
+// findPlaceholders returns the non-overlapping spans of the placeholders of
+// the given styles, in order of appearance. Longer matches win over shorter
+// ones starting at the same position.
+func findPlaceholders(text string, style PlaceholderStyle) [][2]int {
+	var spans [][2]int
+
+	if style&PlaceholderPrintf != 0 {
+		for _, loc := range printfPattern.FindAllStringIndex(text, -1) {
+			spans = append(spans, [2]int{loc[0], loc[1]})
+		}
+	}
+
+	if style&PlaceholderBraces != 0 {
+		for _, loc := range bracesPattern.FindAllStringIndex(text, -1) {
+			spans = append(spans, [2]int{loc[0], loc[1]})
+		}
+	}
+
+	if style&PlaceholderICU != 0 {
+		for _, loc := range icuStartPattern.FindAllStringIndex(text, -1) {
+			if end := matchingBrace(text, loc[0]); end > 0 {
+				spans = append(spans, [2]int{loc[0], end})
+			}
+		}
+	}
+
+	return mergeSpans(spans)
+}
+
+// mergeSpans sorts spans in order of appearance and drops the ones
+// overlapping a previous span. Longer spans win over shorter ones starting at
+// the same position.
+func mergeSpans(spans [][2]int) [][2]int {
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i][0] != spans[j][0] {
+			return spans[i][0] < spans[j][0]
+		}
+
+		return spans[i][1] > spans[j][1]
+	})
+
+	merged := spans[:0]
+	for _, span := range spans {
+		if len(merged) == 0 || span[0] >= merged[len(merged)-1][1] {
+			merged = append(merged, span)
+		}
+	}
+
+	return merged
+}
+
+// matchingBrace returns the position after the brace closing the one at the
+// given position, or -1 if the braces are unbalanced.
+func matchingBrace(text string, start int) int {
+	depth := 0
+
+	for i := start; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,60 @@
+package libretranslate
+
+import (
+	"context"
+	"testing"
+)
+
+func TestProtectPlaceholdersWithExistingTokens(t *testing.T) {
+	c := NewClientWithBaseURL("http://localhost:5000", "key", WithPlaceholderProtection(PlaceholderPrintf))
+
+	tests := []struct {
+		name       string
+		text       string
+		wantMasked string
+		translated string
+		want       string
+	}{
+		{"no tokens", "Hello %s", "Hello [[0]]", "HELLO [[0]]", "HELLO %s"},
+		{"existing token", "[[0]] has %d items", "[[0]] has [[1]] items", "[[0]] HAS [[ 1 ]] ITEMS", "[[0]] HAS %d ITEMS"},
+		{"existing tokens out of order", "%s [[4]] [[2]]", "[[5]] [[4]] [[2]]", "[[4]] [[2]] [[5]]", "[[4]] [[2]] %s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protected := c.protectPlaceholders(tt.text)
+			if protected.text != tt.wantMasked {
+				t.Fatalf("got masked text %q, want %q", protected.text, tt.wantMasked)
+			}
+
+			got, warnings := protected.restore(tt.translated)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+
+			if len(warnings) > 0 {
+				t.Errorf("got warnings %q, want none", warnings)
+			}
+		})
+	}
+}
+
+func TestTranslateMarkdownWithPlaceholderProtection(t *testing.T) {
+	srv := batchServer(t)
+	defer srv.Close()
+
+	c := NewClientWithBaseURL(srv.URL, "key", WithPlaceholderProtection(PlaceholderPrintf))
+
+	result, err := c.TranslateMarkdownDetailed(context.Background(), "Call `run()` with %s and [[7]] here\n", "en", "es")
+	if err != nil {
+		t.Fatalf("TranslateMarkdownDetailed: %v", err)
+	}
+
+	if want := "CALL `run()` WITH %s AND [[7]] HERE\n"; result.Text != want {
+		t.Errorf("got %q, want %q", result.Text, want)
+	}
+
+	if len(result.Skipped) > 0 {
+		t.Errorf("got skipped segments %q, want none", result.Skipped)
+	}
+}
@@ -0,0 +1,203 @@
+package libretranslate
+
+import (
+	"context"
+	"strings"
+)
+
+// SimilarityFunc scores how similar two texts are, from 0 (unrelated) to 1 (identical).
+type SimilarityFunc func(a, b string) float64
+
+// WithSimilarity sets the metric RoundTrip and QualityReport use to compare
+// the original text with its back translation. The default is
+// LevenshteinSimilarity.
+func WithSimilarity(similarity SimilarityFunc) Option {
+	return func(c *Client) {
+		c.similarity = similarity
+	}
+}
+
+// RoundTripResult represents the result of a round-trip translation.
+type RoundTripResult struct {
+	// Translation from the source to the target language
+	Forward string
+	// Translation of Forward back to the source language
+	Back string
+	// Similarity between the original text and Back
+	Similarity float64
+}
+
+// RoundTrip translates a text to the target language and back to the source
+// language, and scores the similarity between the original text and the back
+// translation. It is a common heuristic of translation quality: a low score
+// suggests meaning was lost.
+//
+// If source is "auto", the text is translated back to the detected language.
+func (c *Client) RoundTrip(query, source, target string) (RoundTripResult, error) {
+	return c.RoundTripContext(context.Background(), query, source, target)
+}
+
+// RoundTripContext is like RoundTrip but uses the given context for the requests.
+func (c *Client) RoundTripContext(ctx context.Context, query, source, target string) (RoundTripResult, error) {
+	forward, err := c.translate(ctx, query, source, target, callOptions{})
+	if err != nil {
+		return RoundTripResult{}, err
+	}
+
+	if source == "auto" {
+		source = forward.DetectedLanguage.Language
+	}
+
+	back, err := c.translate(ctx, forward.TranslatedText, target, source, callOptions{})
+	if err != nil {
+		return RoundTripResult{}, err
+	}
+
+	return RoundTripResult{
+		Forward:    forward.TranslatedText,
+		Back:       back.TranslatedText,
+		Similarity: c.similarityFunc()(query, back.TranslatedText),
+	}, nil
+}
+
+// similarityFunc returns the configured similarity metric or the default one.
+func (c *Client) similarityFunc() SimilarityFunc {
+	if c.similarity != nil {
+		return c.similarity
+	}
+
+	return LevenshteinSimilarity
+}
+
+// LevenshteinSimilarity returns 1 minus the edit distance between the
+// lowercased texts divided by the length of the longest one, counted in runes.
+func LevenshteinSimilarity(a, b string) float64 {
+	ra := []rune(strings.ToLower(strings.TrimSpace(a)))
+	rb := []rune(strings.ToLower(strings.TrimSpace(b)))
+
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+// DefaultQualityThreshold is the similarity below which QualityReport flags
+// a translation as low quality, unless another threshold is set with
+// WithQualityThreshold.
+const DefaultQualityThreshold = 0.5
+
+// WithQualityThreshold sets the similarity below which QualityReport flags a
+// translation as low quality.
+func WithQualityThreshold(threshold float64) Option {
+	return func(c *Client) {
+		c.qualityThreshold = &threshold
+	}
+}
+
+// QualityScore represents the round-trip score of a text in a quality report.
+type QualityScore struct {
+	// Original text
+	Query string
+	// Translation from the source to the target language
+	Forward string
+	// Translation of Forward back to the source language
+	Back string
+	// Similarity between the original text and Back
+	Score float64
+	// Whether Score is below the quality threshold of the client
+	Low bool
+}
+
+// QualityReport scores the translations of several texts like RoundTrip, to
+// screen a dataset before human review, with one batch request for the
+// forward translations and one for the back translations (per detected
+// language if source is "auto"). The texts are compared with the metric set
+// by WithSimilarity, and the ones scoring below the threshold set by
+// WithQualityThreshold are flagged as low quality.
+func (c *Client) QualityReport(queries []string, source, target string) ([]QualityScore, error) {
+	return c.QualityReportContext(context.Background(), queries, source, target)
+}
+
+// QualityReportContext is like QualityReport but uses the given context for the requests.
+func (c *Client) QualityReportContext(ctx context.Context, queries []string, source, target string) ([]QualityScore, error) {
+	forward, err := c.translateBatch(ctx, queries, source, target, callOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Group the translations by the language to translate them back into.
+	var languages []string
+
+	groups := make(map[string][]int)
+	for i, result := range forward {
+		language := source
+		if source == "auto" {
+			language = result.DetectedLanguage.Language
+		}
+
+		if _, ok := groups[language]; !ok {
+			languages = append(languages, language)
+		}
+
+		groups[language] = append(groups[language], i)
+	}
+
+	threshold := DefaultQualityThreshold
+	if c.qualityThreshold != nil {
+		threshold = *c.qualityThreshold
+	}
+
+	similarity := c.similarityFunc()
+	scores := make([]QualityScore, len(queries))
+
+	for _, language := range languages {
+		indexes := groups[language]
+
+		texts := make([]string, len(indexes))
+		for j, i := range indexes {
+			texts[j] = forward[i].TranslatedText
+		}
+
+		back, err := c.translateBatch(ctx, texts, target, language, callOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		for j, i := range indexes {
+			score := similarity(queries[i], back[j].TranslatedText)
+			scores[i] = QualityScore{
+				Query:   queries[i],
+				Forward: forward[i].TranslatedText,
+				Back:    back[j].TranslatedText,
+				Score:   score,
+				Low:     score < threshold,
+			}
+		}
+	}
+
+	return scores, nil
+}
@@ -0,0 +1,57 @@
+package libretranslate
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// WithLogger sets the logger reporting the errors of background work, such
+// as the cache refresher, and the retries of the requests (at debug level).
+// By default nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// StartCacheRefresher starts a goroutine fetching the settings and the
+// languages of the instance every interval (and once right away), so that the
+// client cache stays warm and up to date without adding latency to the calls
+// using it.
+//
+// The goroutine stops when the context is done, which closes the returned
+// channel. Failed refreshes keep the cached values and are logged with the
+// logger of the client.
+//
+// A zero or negative interval disables the refresher: nothing is fetched and
+// the returned channel is already closed.
+func (c *Client) StartCacheRefresher(ctx context.Context, interval time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+
+	if interval <= 0 {
+		close(done)
+		return done
+	}
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, _, err := c.Initialize(ctx); err != nil && ctx.Err() == nil {
+				c.log(ctx, slog.LevelWarn, "libretranslate: cache refresh failed", "error", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return done
+}
@@ -0,0 +1,56 @@
+package libretranslate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStartCacheRefresherNonPositiveInterval(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := NewClientWithBaseURL(srv.URL, "key")
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		done := c.StartCacheRefresher(context.Background(), interval)
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("the refresher with interval %s is running, want it disabled", interval)
+		}
+	}
+
+	if got := calls.Load(); got != 0 {
+		t.Errorf("got %d requests, want none", got)
+	}
+}
+
+func TestStartCacheRefresherStops(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := NewClientWithBaseURL(srv.URL, "key")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := c.StartCacheRefresher(ctx, time.Millisecond)
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the refresher did not stop after the context was canceled")
+	}
+}
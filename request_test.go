@@ -0,0 +1,115 @@
+package libretranslate
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetriedRequestBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		want        string
+	}{
+		{
+			"form",
+			DefaultContentType,
+			"api_key=key&q=hello+world&source=en&target=es",
+		},
+		{
+			"json",
+			"application/json",
+			`{"api_key":"key","q":"hello world","source":"en","target":"es"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu     sync.Mutex
+				bodies []string
+			)
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("reading the request body: %v", err)
+				}
+
+				mu.Lock()
+				bodies = append(bodies, string(body))
+				attempt := len(bodies)
+				mu.Unlock()
+
+				if attempt == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+
+				w.Write([]byte(`{"translatedText":"hola mundo"}`))
+			}))
+			defer srv.Close()
+
+			c := NewClientWithBaseURL(srv.URL, "key", WithContentType(tt.contentType), WithRetry(2, time.Millisecond))
+
+			if _, err := c.Translate("hello world", "en", "es"); err != nil {
+				t.Fatalf("Translate: %v", err)
+			}
+
+			if len(bodies) != 2 {
+				t.Fatalf("got %d requests, want 2", len(bodies))
+			}
+
+			for i, body := range bodies {
+				if body != tt.want {
+					t.Errorf("attempt %d: got body %q, want %q", i+1, body, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestGetBody(t *testing.T) {
+	c := NewClientWithBaseURL("http://localhost:5000", "key")
+
+	params := url.Values{"q": {"hello"}, "source": {"en"}, "target": {"es"}}
+
+	req, err := c.buildRequest(context.Background(), nil, http.MethodPost, "/translate", params)
+	if err != nil {
+		t.Fatalf("buildRequest: %v", err)
+	}
+
+	first, _ := io.ReadAll(req.Body)
+
+	body, err := req.GetBody()
+	if err != nil {
+		t.Fatalf("GetBody: %v", err)
+	}
+
+	second, _ := io.ReadAll(body)
+
+	if len(first) == 0 || string(first) != string(second) {
+		t.Errorf("GetBody returned %q after the body %q", second, first)
+	}
+}
+
+func BenchmarkBuildRequest(b *testing.B) {
+	c := NewClientWithBaseURL("http://localhost:5000", "key")
+
+	params := url.Values{"q": {"hello world"}, "source": {"en"}, "target": {"es"}, "format": {"text"}}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := c.buildRequest(ctx, nil, http.MethodPost, "/translate", params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
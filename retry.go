@@ -0,0 +1,342 @@
+package libretranslate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxRetryDelay caps the delay between two attempts of the same request,
+// unless set with WithRetryMaxDelay or asked by a Retry-After header within
+// the retry budget.
+const maxRetryDelay = 30 * time.Second
+
+// retryPolicy describes how failed requests are retried.
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	budget      time.Duration
+}
+
+// WithRetry makes the client retry requests that fail because of a network
+// error, a rate limit (429) or an unavailable server (502, 503, 504), up to
+// maxAttempts attempts in total.
+//
+// The delay between attempts grows exponentially from baseDelay; with a zero
+// baseDelay, requests are retried right away. When the server sends a
+// Retry-After header, the client waits at least that long.
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		c.retry.maxAttempts = maxAttempts
+		c.retry.baseDelay = baseDelay
+	}
+}
+
+// WithRetryMaxDelay caps the delay between two attempts of a request, which
+// is 30 seconds by default. A Retry-After header asking for a longer delay
+// still applies, within the retry budget set with WithRetryBudget; without a
+// budget, it is capped at the max delay or 30 seconds, whichever is longer.
+func WithRetryMaxDelay(maxDelay time.Duration) Option {
+	return func(c *Client) {
+		c.retry.maxDelay = maxDelay
+	}
+}
+
+// WithRetryBudget bounds the total time spent on a request and its retries.
+// When the next attempt would start after the budget is exhausted, the error
+// of the last attempt is returned without waiting. A retry that would start
+// after the deadline of the context is not attempted either.
+func WithRetryBudget(maxTotal time.Duration) Option {
+	return func(c *Client) {
+		c.retry.budget = maxTotal
+	}
+}
+
+// ErrTransientResult is returned when translations still match the pattern
+// set with WithRetryOnResultPattern after the last attempt.
+var ErrTransientResult = errors.New("transient translation result")
+
+// WithRetryOnResultPattern makes the client retry the translation requests
+// whose response has a translation matching the given pattern, such as the
+// "MODEL_LOADING" placeholder some instances return with a 200 status while
+// they warm up. This is specific to these instances: the API does not define
+// such results.
+//
+// The retries follow the policy set with WithRetry (and the retry budget and
+// maximum delay), so without WithRetry such a response fails right away. If
+// the translations still match after the last attempt, or the next attempt
+// would exceed the budget or the deadline of the context, the call fails with
+// ErrTransientResult.
+func WithRetryOnResultPattern(pattern *regexp.Regexp) Option {
+	return func(c *Client) {
+		c.retryResultPattern = pattern
+	}
+}
+
+// retryOnResult calls send, which sends a translation request, again while
+// one of the translations it returns matches the pattern set with
+// WithRetryOnResultPattern.
+func (c *Client) retryOnResult(ctx context.Context, send func() ([]TranslateResult, error)) ([]TranslateResult, error) {
+	if c.retryResultPattern == nil {
+		return send()
+	}
+
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		results, err := send()
+		if err != nil {
+			return nil, err
+		}
+
+		i := slices.IndexFunc(results, func(result TranslateResult) bool {
+			return c.retryResultPattern.MatchString(result.TranslatedText)
+		})
+		if i < 0 {
+			return results, nil
+		}
+
+		err = fmt.Errorf("%w: %q", ErrTransientResult, results[i].TranslatedText)
+
+		delay := c.retry.backoff(attempt)
+		if attempt >= c.retry.maxAttempts || !c.retry.canWait(ctx, start, delay) {
+			return nil, err
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// do sends a request to the given endpoint, retrying it according to the
+// retry policy of the client, and returns the successful response.
+//
+// Every public method goes through do, so retries and rate limits are handled
+// the same way for all endpoints. The request is built again for each attempt,
+// so a retry never sends a body consumed by a previous attempt and can be sent
+// to another backend.
+func (c *Client) do(ctx context.Context, method, endpoint string, params url.Values) (*http.Response, error) {
+	if err := c.lifecycle.begin(); err != nil {
+		return nil, err
+	}
+
+	ctx, release := c.lifecycle.bind(ctx)
+	ctx, cancelTimeout := c.endpointContext(ctx, endpoint)
+
+	// The request stays in flight for Shutdown until its response is read,
+	// so the release runs once, when the body is closed or the request fails.
+	cancel := sync.OnceFunc(func() {
+		if cancelTimeout != nil {
+			cancelTimeout()
+		}
+
+		release()
+		c.lifecycle.end()
+	})
+
+	res, err := c.attempt(ctx, method, endpoint, params)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// CancelAll and the timeout also abort the reading of the response.
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+
+	return res, nil
+}
+
+// attempt sends a request until it succeeds or the retry policy gives up.
+func (c *Client) attempt(ctx context.Context, method, endpoint string, params url.Values) (*http.Response, error) {
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		backend := c.backends.next()
+
+		req, err := c.buildRequest(ctx, backend, method, endpoint, params)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := c.handle(req)
+		if err == nil && res.StatusCode != http.StatusOK {
+			// The chain of the client does not check the responses.
+			err = checkForResponseErrors(res, params)
+		}
+
+		var apiErr *APIError
+		isAPIError := errors.As(err, &apiErr)
+
+		if isAPIError && c.errorContext {
+			apiErr.detailed = true
+		}
+
+		if isAPIError && apiErr.StatusCode == http.StatusNoContent && c.errorOnEmpty && isTranslateEndpoint(endpoint) {
+			apiErr.kind = ErrEmptyTranslation
+		}
+
+		if ctx.Err() == nil {
+			c.backends.report(backend, !errors.Is(err, ErrConnection) && !(isAPIError && isUnavailableStatus(apiErr.StatusCode)))
+		}
+
+		if isAPIError && apiErr.StatusCode == http.StatusUnsupportedMediaType && req.Header.Get("Content-Encoding") == "gzip" {
+			// The server does not accept compressed bodies: send the request
+			// again uncompressed, without counting it as an attempt.
+			c.compressionRejected.Store(true)
+			attempt--
+
+			continue
+		}
+
+		if err == nil {
+			return res, nil
+		}
+
+		if errors.Is(err, ErrInvalidAPIKey) {
+			c.keys.invalidate()
+		}
+
+		if !errors.Is(err, ErrConnection) && !(isAPIError && isRetryableStatus(apiErr.StatusCode)) {
+			return nil, err
+		}
+
+		delay := c.retry.backoff(attempt)
+		if isAPIError {
+			delay = max(delay, retryAfter(apiErr.header, c.retry.retryAfterLimit()))
+		}
+
+		if attempt >= c.retry.maxAttempts || ctx.Err() != nil || !c.retry.canWait(ctx, start, delay) {
+			return nil, err
+		}
+
+		c.log(ctx, slog.LevelDebug, "libretranslate: retrying request",
+			"endpoint", endpoint, "attempt", attempt, "delay", delay, "error", err)
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff returns the delay to wait after the given failed attempt.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	maxDelay := p.maxDelay
+	if maxDelay <= 0 {
+		maxDelay = maxRetryDelay
+	}
+
+	if p.baseDelay <= 0 {
+		return 0
+	}
+
+	delay := p.baseDelay << (attempt - 1)
+	if delay <= 0 || delay>>(attempt-1) != p.baseDelay {
+		// The shift overflowed after many attempts.
+		delay = maxDelay
+	}
+
+	delay = min(delay, maxDelay)
+
+	// Wait between half and the full delay so concurrent clients spread out.
+	half := delay / 2
+
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retryAfterLimit returns the longest Retry-After delay the client honors.
+// Longer delays are cut to it, and canWait then gives up if it exceeds the
+// budget.
+func (p retryPolicy) retryAfterLimit() time.Duration {
+	if p.budget > 0 {
+		return p.budget
+	}
+
+	return max(p.maxDelay, maxRetryDelay)
+}
+
+// canWait reports whether a request started at the given time can wait for
+// the given delay before its next attempt, without exceeding the retry budget
+// or the deadline of the context.
+func (p retryPolicy) canWait(ctx context.Context, start time.Time, delay time.Duration) bool {
+	next := time.Now().Add(delay)
+
+	if p.budget > 0 && next.Sub(start) > p.budget {
+		return false
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && next.After(deadline) {
+		return false
+	}
+
+	return true
+}
+
+// isRetryableStatus reports whether a response with the given status code is worth retrying.
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || isUnavailableStatus(code)
+}
+
+// isUnavailableStatus reports whether the given status code means the server is unavailable.
+func isUnavailableStatus(code int) bool {
+	switch code {
+	case http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// retryAfter parses the Retry-After header, which holds either a number of
+// seconds or an HTTP date, capping the delay at the given limit. It returns
+// zero if the header is missing or invalid.
+func retryAfter(header http.Header, limit time.Duration) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+
+		// Compare in seconds so a huge value does not overflow.
+		if int64(seconds) > int64(limit/time.Second) {
+			return limit
+		}
+
+		return min(time.Duration(seconds)*time.Second, limit)
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return min(max(time.Until(date), 0), limit)
+	}
+
+	return 0
+}
+
+// sleep waits for the given duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
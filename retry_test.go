@@ -0,0 +1,205 @@
+package libretranslate
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  retryPolicy
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{"zero base delay", retryPolicy{}, 1, 0, 0},
+		{"zero base delay later attempt", retryPolicy{}, 5, 0, 0},
+		{"first attempt", retryPolicy{baseDelay: time.Second}, 1, 500 * time.Millisecond, time.Second},
+		{"third attempt", retryPolicy{baseDelay: time.Second}, 3, 2 * time.Second, 4 * time.Second},
+		{"capped by max delay", retryPolicy{baseDelay: time.Second, maxDelay: 3 * time.Second}, 5, 1500 * time.Millisecond, 3 * time.Second},
+		{"capped by default max delay", retryPolicy{baseDelay: time.Second}, 10, maxRetryDelay / 2, maxRetryDelay},
+		{"overflow", retryPolicy{baseDelay: time.Second}, 100, maxRetryDelay / 2, maxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay := tt.policy.backoff(tt.attempt)
+			if delay < tt.min || delay > tt.max {
+				t.Errorf("backoff(%d) = %s, want between %s and %s", tt.attempt, delay, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestDetectRetriesRateLimit(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/detect" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":"Slowdown: 1 per 1 second"}`))
+
+			return
+		}
+
+		w.Write([]byte(`[{"confidence":90,"language":"en"}]`))
+	}))
+	defer srv.Close()
+
+	c := NewClientWithBaseURL(srv.URL, "key", WithRetry(3, time.Millisecond))
+
+	detections, err := c.Detect("hello")
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+
+	if len(detections) != 1 || detections[0].Language != "en" {
+		t.Errorf("got detections %+v, want en", detections)
+	}
+}
+
+func TestDetectRateLimitWithoutRetry(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":"Slowdown: 1 per 1 second"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClientWithBaseURL(srv.URL, "key")
+
+	if _, err := c.Detect("hello"); err == nil {
+		t.Fatal("Detect succeeded, want a rate limit error")
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		limit time.Duration
+		want  time.Duration
+	}{
+		{"missing", "", time.Minute, 0},
+		{"invalid", "soon", time.Minute, 0},
+		{"negative", "-5", time.Minute, 0},
+		{"seconds", "45", time.Minute, 45 * time.Second},
+		{"seconds above the default max delay", "45", 2 * time.Minute, 45 * time.Second},
+		{"capped", "120", time.Minute, time.Minute},
+		{"huge", "9223372036854775807", time.Minute, time.Minute},
+		{"past date", "Mon, 02 Jan 2006 15:04:05 GMT", time.Minute, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := make(http.Header)
+			if tt.value != "" {
+				header.Set("Retry-After", tt.value)
+			}
+
+			if got := retryAfter(header, tt.limit); got != tt.want {
+				t.Errorf("retryAfter(%q, %s) = %s, want %s", tt.value, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfterLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy retryPolicy
+		want   time.Duration
+	}{
+		{"default", retryPolicy{}, maxRetryDelay},
+		{"short max delay", retryPolicy{maxDelay: time.Second}, maxRetryDelay},
+		{"long max delay", retryPolicy{maxDelay: time.Minute}, time.Minute},
+		{"budget", retryPolicy{maxDelay: time.Second, budget: 5 * time.Minute}, 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.retryAfterLimit(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfterAboveMaxDelay(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":"Slowdown: 1 per 1 second"}`))
+
+			return
+		}
+
+		w.Write([]byte(`{"translatedText":"hola"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClientWithBaseURL(srv.URL, "key",
+		WithRetry(2, time.Millisecond),
+		WithRetryMaxDelay(10*time.Millisecond),
+		WithRetryBudget(5*time.Second),
+	)
+
+	start := time.Now()
+
+	if _, err := c.Translate("hello", "en", "es"); err != nil {
+		t.Fatalf("Translate: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, want at least the Retry-After delay of 1s", elapsed)
+	}
+}
+
+func TestRetryAfterBeyondBudget(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":"Slowdown: 1 per 2 minutes"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClientWithBaseURL(srv.URL, "key", WithRetry(3, time.Millisecond), WithRetryBudget(time.Minute))
+
+	start := time.Now()
+
+	if _, err := c.Translate("hello", "en", "es"); err == nil {
+		t.Fatal("Translate succeeded, want a rate limit error")
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("gave up after %s, want right away", elapsed)
+	}
+}
@@ -0,0 +1,74 @@
+package libretranslate
+
+import (
+	"maps"
+	"strings"
+)
+
+// rtlLanguages holds the codes of the languages written right to left. The
+// API does not report the direction of the languages, so this list, based on
+// the default scripts of the Unicode CLDR, is the source of truth of IsRTL.
+var rtlLanguages = map[string]bool{
+	"ar":  true, // Arabic
+	"arc": true, // Aramaic
+	"ckb": true, // Central Kurdish
+	"dv":  true, // Divehi
+	"fa":  true, // Persian
+	"he":  true, // Hebrew
+	"iw":  true, // Hebrew (former code)
+	"ks":  true, // Kashmiri
+	"ps":  true, // Pashto
+	"sd":  true, // Sindhi
+	"ug":  true, // Uyghur
+	"ur":  true, // Urdu
+	"yi":  true, // Yiddish
+}
+
+// RTLLanguages returns a copy of the built-in set of languages written right
+// to left, for instance to extend it with custom languages and pass it to
+// WithRTLLanguages.
+func RTLLanguages() map[string]bool {
+	return maps.Clone(rtlLanguages)
+}
+
+// WithRTLLanguages replaces the built-in set of languages written right to
+// left used by Client.IsRTL, for instances serving custom languages. The
+// codes are matched without their region and script subtags, in lower case.
+func WithRTLLanguages(languages map[string]bool) Option {
+	return func(c *Client) {
+		c.rtlLanguages = make(map[string]bool, len(languages))
+		for code, rtl := range languages {
+			c.rtlLanguages[strings.ToLower(code)] = rtl
+		}
+	}
+}
+
+// IsRTL reports whether the language is written right to left, according to
+// the built-in set (see RTLLanguages). Region and script subtags, as in
+// "fa-AF", are ignored.
+func (l Language) IsRTL() bool {
+	return IsRTL(l.Code)
+}
+
+// IsRTL reports whether the language with the given code is written right to
+// left, according to the built-in set (see RTLLanguages).
+func IsRTL(code string) bool {
+	return isRTL(rtlLanguages, code)
+}
+
+// IsRTL reports whether the language with the given code is written right to
+// left, according to the set given to WithRTLLanguages, or the built-in one.
+func (c *Client) IsRTL(code string) bool {
+	if c.rtlLanguages != nil {
+		return isRTL(c.rtlLanguages, code)
+	}
+
+	return IsRTL(code)
+}
+
+// isRTL reports whether the language with the given code is in the given set.
+func isRTL(languages map[string]bool, code string) bool {
+	base, _, _ := strings.Cut(strings.ToLower(code), "-")
+
+	return languages[base]
+}
@@ -0,0 +1,43 @@
+package libretranslate
+
+import "testing"
+
+func TestIsRTL(t *testing.T) {
+	custom := RTLLanguages()
+	custom["syc"] = true
+	delete(custom, "yi")
+
+	defaults := NewClientWithBaseURL("http://localhost:5000", "key")
+	c := NewClientWithBaseURL("http://localhost:5000", "key", WithRTLLanguages(custom))
+
+	tests := []struct {
+		code       string
+		want       bool
+		wantClient bool
+	}{
+		{"ar", true, true},
+		{"fa-AF", true, true},
+		{"HE", true, true},
+		{"en", false, false},
+		{"syc", false, true},
+		{"yi", true, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsRTL(tt.code); got != tt.want {
+			t.Errorf("IsRTL(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+
+		if got := defaults.IsRTL(tt.code); got != tt.want {
+			t.Errorf("Client.IsRTL(%q) with the defaults = %v, want %v", tt.code, got, tt.want)
+		}
+
+		if got := c.IsRTL(tt.code); got != tt.wantClient {
+			t.Errorf("Client.IsRTL(%q) with WithRTLLanguages = %v, want %v", tt.code, got, tt.wantClient)
+		}
+	}
+
+	if RTLLanguages()["syc"] {
+		t.Error("modifying the copy returned by RTLLanguages changed the built-in set")
+	}
+}
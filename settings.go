@@ -0,0 +1,123 @@
+package libretranslate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// Settings represents the result for the frontend settings query.
+type Settings struct {
+	// Whether the instance supports API keys
+	APIKeys bool `json:"apiKeys"`
+	// Maximum number of characters per request (-1 for unlimited)
+	CharLimit int `json:"charLimit"`
+	// Whether file translation is enabled
+	FilesTranslation bool `json:"filesTranslation"`
+	// Maximum size in bytes of the files accepted for file translation (0 if
+	// not reported by the instance)
+	FileSizeLimit int64 `json:"fileSizeLimit"`
+	// Delay (in milliseconds) used by the web frontend between requests
+	FrontendTimeout int `json:"frontendTimeout"`
+	// Whether an API key is required to use the instance
+	KeyRequired bool `json:"keyRequired"`
+	// Default languages of the web frontend
+	Language struct {
+		Source Language `json:"source"`
+		Target Language `json:"target"`
+	} `json:"language"`
+	// Whether the instance accepts suggestions
+	Suggestions bool `json:"suggestions"`
+	// File extensions accepted for file translation
+	SupportedFilesFormat []string `json:"supportedFilesFormat"`
+}
+
+// GetSettings makes a request to retrieve the settings of the instance.
+func (c *Client) GetSettings() (Settings, error) {
+	return c.GetSettingsContext(context.Background())
+}
+
+// GetSettingsContext is like GetSettings but uses the given context for the request.
+// A successful response is stored in the client cache.
+func (c *Client) GetSettingsContext(ctx context.Context) (Settings, error) {
+	key, err := c.apiKey(ctx, "")
+	if err != nil {
+		return Settings{}, err
+	}
+
+	params := url.Values{}
+	params.Set("api_key", key)
+
+	res, err := c.do(ctx, http.MethodGet, "/frontend/settings", params)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	defer res.Body.Close()
+
+	result := Settings{}
+	if err := c.decode(res, &result); err != nil {
+		return Settings{}, err
+	}
+
+	c.cache.setSettings(result)
+
+	return result, nil
+}
+
+// Initialize fetches the settings and the supported languages of the instance
+// concurrently and stores them in the client cache.
+//
+// If any of the requests fails, the returned error reports which one.
+func (c *Client) Initialize(ctx context.Context) (Settings, []Language, error) {
+	var (
+		wg           sync.WaitGroup
+		settings     Settings
+		languages    []Language
+		settingsErr  error
+		languagesErr error
+	)
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		settings, settingsErr = c.GetSettingsContext(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		languages, languagesErr = c.GetLanguagesContext(ctx)
+	}()
+
+	wg.Wait()
+
+	if settingsErr != nil {
+		settingsErr = fmt.Errorf("settings: %w", settingsErr)
+	}
+
+	if languagesErr != nil {
+		languagesErr = fmt.Errorf("languages: %w", languagesErr)
+	}
+
+	if err := errors.Join(settingsErr, languagesErr); err != nil {
+		return Settings{}, nil, fmt.Errorf("initialization error: %w", err)
+	}
+
+	return settings, languages, nil
+}
+
+// RequiresAPIKey reports whether the instance requires an API key, which lets
+// applications decide whether to ask users for one. The settings are fetched
+// on the first call and then read from the client cache.
+func (c *Client) RequiresAPIKey(ctx context.Context) (bool, error) {
+	settings, err := c.settings(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return settings.KeyRequired, nil
+}
@@ -0,0 +1,123 @@
+package libretranslate
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClientClosed is returned for the requests made after Shutdown was called.
+var ErrClientClosed = errors.New("client closed")
+
+// Shutdown stops the client from sending new requests, which fail with
+// ErrClientClosed, and waits for the requests in flight (including their
+// retries) to complete or for the context to be done, in which case the
+// context error is returned.
+//
+// A call that needs several requests, such as TranslateLong, fails if it
+// sends one of them after Shutdown was called. Shutdown can be called several
+// times; the client cannot be reopened, but Clone returns an open client.
+func (c *Client) Shutdown(ctx context.Context) error {
+	select {
+	case <-c.lifecycle.close():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CancelAll cancels the requests in flight, including their retries and the
+// reading of their responses, which fail with context.Canceled. Unlike
+// Shutdown, it does not close the client: the requests started after
+// CancelAll returns are not affected, including the next requests of a call
+// made of several requests, such as TranslateConcurrent. To abort such a
+// call, cancel its context.
+func (c *Client) CancelAll() {
+	c.lifecycle.cancelAll()
+}
+
+// lifecycle tracks the requests in flight of a client, to drain them on
+// shutdown and cancel them with CancelAll.
+type lifecycle struct {
+	mu     sync.Mutex
+	closed bool
+	active int
+	// idle is closed once the client is closed and no request is in flight
+	idle chan struct{}
+	// scope is canceled by CancelAll, and then replaced
+	scope       context.Context
+	cancelScope context.CancelFunc
+}
+
+// begin registers a new request, or fails if the client is closed.
+func (l *lifecycle) begin() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClientClosed
+	}
+
+	l.active++
+
+	return nil
+}
+
+// end unregisters a request registered by begin.
+func (l *lifecycle) end() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.active--
+	if l.closed && l.active == 0 {
+		close(l.idle)
+	}
+}
+
+// close closes the client and returns a channel closed once no request is in flight.
+func (l *lifecycle) close() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.closed {
+		l.closed = true
+		l.idle = make(chan struct{})
+
+		if l.active == 0 {
+			close(l.idle)
+		}
+	}
+
+	return l.idle
+}
+
+// bind returns a copy of the context of a request, canceled by CancelAll, and
+// the function releasing it once the request is done.
+func (l *lifecycle) bind(ctx context.Context) (context.Context, context.CancelFunc) {
+	l.mu.Lock()
+	if l.scope == nil {
+		l.scope, l.cancelScope = context.WithCancel(context.Background())
+	}
+
+	scope := l.scope
+	l.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(scope, cancel)
+
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// cancelAll cancels the contexts returned by bind so far.
+func (l *lifecycle) cancelAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.cancelScope != nil {
+		l.cancelScope()
+		l.scope, l.cancelScope = nil, nil
+	}
+}
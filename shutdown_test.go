@@ -0,0 +1,62 @@
+package libretranslate
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestShutdownWaitsForResponseBody(t *testing.T) {
+	written := make(chan struct{})
+	resume := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"translatedText":`))
+		w.(http.Flusher).Flush()
+		close(written)
+		<-resume
+		w.Write([]byte(`"hola"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClientWithBaseURL(srv.URL, "key")
+
+	type result struct {
+		text string
+		err  error
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		text, err := c.Translate("hello", "en", "es")
+		done <- result{text, err}
+	}()
+
+	<-written
+
+	// The headers are read, but the body is still in flight.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown returned %v while the body was in flight, want %v", err, context.DeadlineExceeded)
+	}
+
+	close(resume)
+
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	if r := <-done; r.err != nil || r.text != "hola" {
+		t.Errorf("Translate = %q, %v, want %q", r.text, r.err, "hola")
+	}
+
+	if _, err := c.Translate("hello", "en", "es"); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Translate after Shutdown returned %v, want %v", err, ErrClientClosed)
+	}
+}
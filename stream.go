@@ -0,0 +1,129 @@
+package libretranslate
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// StreamResult represents the translation of a message sent to Stream.
+type StreamResult struct {
+	// Original message
+	Query string
+	// Translated message (empty if Err is set)
+	TranslatedText string
+	// Error of the translation of the message
+	Err error
+}
+
+// Stream translates the messages received on the given channel and sends
+// their translations, in the same order, on the returned channel. Messages
+// received within window of the first message of a batch are translated
+// together with a single request, which reduces the number of calls when
+// messages arrive in bursts at the cost of up to window of extra latency.
+//
+// The returned channel is closed once the input channel is closed and all its
+// messages are translated, or when the context is done, in which case pending
+// messages are dropped. A failed request does not stop the stream: its error
+// is reported in the results of its messages. When only some texts of a batch
+// fail (see BatchError), the other messages keep their translations.
+func (c *Client) Stream(ctx context.Context, messages <-chan string, source, target string, window time.Duration) <-chan StreamResult {
+	return streamBatches(ctx, messages, window, func(ctx context.Context, batch []string) ([]string, error) {
+		results, err := c.translateBatch(ctx, batch, source, target, callOptions{})
+
+		var texts []string
+		if results != nil {
+			texts = make([]string, len(results))
+			for i, result := range results {
+				texts[i] = result.TranslatedText
+			}
+		}
+
+		return texts, err
+	})
+}
+
+// streamBatches runs a stream of messages for Stream, translating each batch
+// with the given function. A *BatchError returned along with the
+// translations fails only the messages it reports.
+func streamBatches(ctx context.Context, messages <-chan string, window time.Duration, translate func(context.Context, []string) ([]string, error)) <-chan StreamResult {
+	results := make(chan StreamResult)
+
+	go func() {
+		defer close(results)
+
+		for {
+			batch, open := collectBatch(ctx, messages, window)
+			if len(batch) > 0 {
+				translated, err := translate(ctx, batch)
+
+				var batchErr *BatchError
+				partial := errors.As(err, &batchErr) && len(translated) == len(batch)
+
+				for i, query := range batch {
+					result := StreamResult{Query: query}
+
+					switch {
+					case partial:
+						if itemErr, failed := batchErr.Errors[i]; failed {
+							result.Err = itemErr
+						} else {
+							result.TranslatedText = translated[i]
+						}
+					case err != nil:
+						result.Err = err
+					default:
+						result.TranslatedText = translated[i]
+					}
+
+					select {
+					case results <- result:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+
+			if !open || ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
+// collectBatch waits for a message and collects the messages received within
+// window of it. It reports whether the channel is still open.
+func collectBatch(ctx context.Context, messages <-chan string, window time.Duration) ([]string, bool) {
+	var batch []string
+
+	select {
+	case message, ok := <-messages:
+		if !ok {
+			return nil, false
+		}
+
+		batch = append(batch, message)
+	case <-ctx.Done():
+		return nil, false
+	}
+
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+
+	for {
+		select {
+		case message, ok := <-messages:
+			if !ok {
+				return batch, false
+			}
+
+			batch = append(batch, message)
+		case <-timer.C:
+			return batch, true
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package libretranslate
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// streamAll sends the messages to the stream of a translator and collects
+// the results.
+func streamAll(t *testing.T, translator Translator, messages ...string) []StreamResult {
+	t.Helper()
+
+	input := make(chan string, len(messages))
+	for _, message := range messages {
+		input <- message
+	}
+
+	close(input)
+
+	var results []StreamResult
+	for result := range translator.Stream(context.Background(), input, "en", "es", 50*time.Millisecond) {
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func TestStreamPartialBatch(t *testing.T) {
+	srv := batchServer(t)
+	defer srv.Close()
+
+	c := NewClientWithBaseURL(srv.URL, "key", WithMaxBatchItems(2))
+
+	results := streamAll(t, c, "a", "b", "fail", "d", "e")
+
+	want := []string{"A", "B", "", "", "E"}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+
+	for i, result := range results {
+		failed := want[i] == ""
+		if failed != (result.Err != nil) || result.TranslatedText != want[i] {
+			t.Errorf("result %d: got %q, %v, want %q", i, result.TranslatedText, result.Err, want[i])
+		}
+	}
+}
+
+func TestStubClientStream(t *testing.T) {
+	results := streamAll(t, NewStubClient(), "Hello", "World")
+
+	want := []string{"[es] Hello", "[es] World"}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+
+	for i, result := range results {
+		if result.Err != nil || result.TranslatedText != want[i] || result.Query != []string{"Hello", "World"}[i] {
+			t.Errorf("result %d: got %+v, want %q", i, result, want[i])
+		}
+	}
+}
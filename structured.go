@@ -0,0 +1,309 @@
+package libretranslate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// WithSkipPattern makes TranslateJSON and TranslateMap leave untranslated the
+// parts of the texts matching the given pattern, such as placeholders ("{name}"),
+// URLs or code. Texts matching the pattern entirely are left untouched, while
+// the text around an embedded match is translated separately.
+func WithSkipPattern(pattern *regexp.Regexp) Option {
+	return func(c *Client) {
+		c.skipPattern = pattern
+	}
+}
+
+// WithSkipKeys makes TranslateJSON leave untranslated the values (and any
+// nested values) of the object keys matching the given pattern.
+func WithSkipKeys(pattern *regexp.Regexp) Option {
+	return func(c *Client) {
+		c.skipKeys = pattern
+	}
+}
+
+// jsonFrame tracks an object or array while walking a JSON document.
+type jsonFrame struct {
+	object    bool
+	expectKey bool
+	key       string
+	skip      bool
+}
+
+// textPiece is a part of a text to translate.
+type textPiece struct {
+	text      string
+	translate bool
+}
+
+// jsonString locates a string value in a JSON document.
+type jsonString struct {
+	start, end int
+	text       string
+}
+
+// TranslateJSON translates the string values of a JSON document from one
+// language to another. Keys, numbers, booleans, nulls, the order of the keys
+// and the formatting of the document are preserved.
+//
+// All the values are translated with a single batch request. Values of keys
+// matching the pattern set with WithSkipKeys are left untouched, and so are
+// the parts of the values matching the pattern set with WithSkipPattern.
+func (c *Client) TranslateJSON(data []byte, source, target string) ([]byte, error) {
+	return c.TranslateJSONContext(context.Background(), data, source, target)
+}
+
+// TranslateJSONContext is like TranslateJSON but uses the given context for the request.
+func (c *Client) TranslateJSONContext(ctx context.Context, data []byte, source, target string) ([]byte, error) {
+	strs, err := c.collectJSONStrings(data)
+	if err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, len(strs))
+	for i, str := range strs {
+		texts[i] = str.text
+	}
+
+	translations, err := c.translateTexts(ctx, texts, source, target)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	offset := 0
+
+	for i, str := range strs {
+		if translations[i] == str.text {
+			continue
+		}
+
+		encoded, err := encodeJSONString(translations[i])
+		if err != nil {
+			return nil, err
+		}
+
+		out.Write(data[offset:str.start])
+		out.Write(encoded)
+		offset = str.end
+	}
+
+	out.Write(data[offset:])
+
+	return out.Bytes(), nil
+}
+
+// TranslateMap translates the values of a map from one language to another
+// with a single batch request. The keys are preserved, and the parts of the
+// values matching the pattern set with WithSkipPattern are left untouched.
+func (c *Client) TranslateMap(m map[string]string, source, target string) (map[string]string, error) {
+	return c.TranslateMapContext(context.Background(), m, source, target)
+}
+
+// TranslateMapContext is like TranslateMap but uses the given context for the request.
+func (c *Client) TranslateMapContext(ctx context.Context, m map[string]string, source, target string) (map[string]string, error) {
+	keys := make([]string, 0, len(m))
+	texts := make([]string, 0, len(m))
+
+	for key, text := range m {
+		keys = append(keys, key)
+		texts = append(texts, text)
+	}
+
+	translations, err := c.translateTexts(ctx, texts, source, target)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(m))
+	for i, key := range keys {
+		result[key] = translations[i]
+	}
+
+	return result, nil
+}
+
+// collectJSONStrings returns the location of the string values to translate in a JSON document.
+func (c *Client) collectJSONStrings(data []byte) ([]jsonString, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var (
+		stack []jsonFrame
+		strs  []jsonString
+	)
+
+	for {
+		before := dec.InputOffset()
+
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("JSON decoding error: %s", err)
+		}
+
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			markJSONValue(stack)
+
+			continue
+		}
+
+		var parent *jsonFrame
+		if len(stack) > 0 {
+			parent = &stack[len(stack)-1]
+		}
+
+		if parent != nil && parent.object && parent.expectKey {
+			parent.key = tok.(string)
+			parent.expectKey = false
+
+			continue
+		}
+
+		skip := parent != nil && (parent.skip || parent.object && c.skipKeys != nil && c.skipKeys.MatchString(parent.key))
+
+		switch v := tok.(type) {
+		case json.Delim:
+			stack = append(stack, jsonFrame{object: v == '{', expectKey: v == '{', skip: skip})
+
+			continue
+		case string:
+			if !skip {
+				// The token is preceded by whitespace and separators only, so
+				// the string starts at the first quote.
+				end := int(dec.InputOffset())
+				start := int(before) + bytes.IndexByte(data[before:end], '"')
+				strs = append(strs, jsonString{start: start, end: end, text: v})
+			}
+		}
+
+		markJSONValue(stack)
+	}
+
+	return strs, nil
+}
+
+// markJSONValue records that the innermost container has read a value.
+func markJSONValue(stack []jsonFrame) {
+	if len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		top.expectKey = top.object
+	}
+}
+
+// translateTexts translates several texts with a single batch request. Each
+// distinct piece of text is sent once, and the parts matching the skip
+// pattern of the client are kept as they are.
+func (c *Client) translateTexts(ctx context.Context, texts []string, source, target string) ([]string, error) {
+	var queries []string
+
+	pieces := make([][]textPiece, len(texts))
+	index := make(map[string]int)
+
+	for i, text := range texts {
+		pieces[i] = c.splitSkipped(text)
+
+		for _, piece := range pieces[i] {
+			if _, ok := index[piece.text]; piece.translate && !ok {
+				index[piece.text] = len(queries)
+				queries = append(queries, piece.text)
+			}
+		}
+	}
+
+	translations, err := c.TranslateBatchContext(ctx, queries, source, target)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]string, len(texts))
+	for i := range texts {
+		var b strings.Builder
+
+		for _, piece := range pieces[i] {
+			if piece.translate {
+				b.WriteString(translations[index[piece.text]])
+			} else {
+				b.WriteString(piece.text)
+			}
+		}
+
+		results[i] = b.String()
+	}
+
+	return results, nil
+}
+
+// splitSkipped splits a text around the matches of the skip pattern of the client.
+func (c *Client) splitSkipped(text string) []textPiece {
+	var pieces []textPiece
+
+	last := 0
+
+	if c.skipPattern != nil {
+		for _, loc := range c.skipPattern.FindAllStringIndex(text, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+
+			pieces = appendTranslatable(pieces, text[last:loc[0]])
+			pieces = append(pieces, textPiece{text: text[loc[0]:loc[1]]})
+			last = loc[1]
+		}
+	}
+
+	return appendTranslatable(pieces, text[last:])
+}
+
+// appendTranslatable appends a piece of text to translate. The surrounding
+// whitespace is kept apart since the server does not preserve it.
+func appendTranslatable(pieces []textPiece, text string) []textPiece {
+	core := strings.TrimSpace(text)
+	if core == "" {
+		if text != "" {
+			pieces = append(pieces, textPiece{text: text})
+		}
+
+		return pieces
+	}
+
+	start := strings.Index(text, core)
+	end := start + len(core)
+
+	if start > 0 {
+		pieces = append(pieces, textPiece{text: text[:start]})
+	}
+
+	pieces = append(pieces, textPiece{text: core, translate: true})
+
+	if end < len(text) {
+		pieces = append(pieces, textPiece{text: text[end:]})
+	}
+
+	return pieces
+}
+
+// encodeJSONString encodes a string as a JSON value without escaping HTML characters.
+func encodeJSONString(s string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(s); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,90 @@
+package libretranslate
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Translator is the interface implemented by Client and StubClient, so code
+// depending on the API can be developed and tested without an instance.
+type Translator interface {
+	DetectContext(ctx context.Context, q string) ([]Detection, error)
+	GetLanguagesContext(ctx context.Context) ([]Language, error)
+	TranslateContext(ctx context.Context, query, source, target string) (string, error)
+	Stream(ctx context.Context, messages <-chan string, source, target string, window time.Duration) <-chan StreamResult
+}
+
+var (
+	_ Translator = (*Client)(nil)
+	_ Translator = (*StubClient)(nil)
+)
+
+// stubLanguages is the list of languages returned by StubClient.
+var stubLanguages = []Language{
+	{Code: "ar", Name: "Arabic"},
+	{Code: "de", Name: "German"},
+	{Code: "en", Name: "English"},
+	{Code: "es", Name: "Spanish"},
+	{Code: "fr", Name: "French"},
+	{Code: "it", Name: "Italian"},
+	{Code: "ja", Name: "Japanese"},
+	{Code: "pt", Name: "Portuguese"},
+	{Code: "ru", Name: "Russian"},
+	{Code: "zh", Name: "Chinese"},
+}
+
+// StubClient is an offline Translator returning deterministic fake results,
+// for development without a running instance.
+type StubClient struct{}
+
+// NewStubClient returns a new stub client.
+func NewStubClient() *StubClient {
+	return &StubClient{}
+}
+
+// DetectContext always detects English with full confidence.
+func (s *StubClient) DetectContext(ctx context.Context, q string) ([]Detection, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return []Detection{{Confidence: 1, Language: "en"}}, nil
+}
+
+// GetLanguagesContext returns a fixed list of common languages.
+func (s *StubClient) GetLanguagesContext(ctx context.Context) ([]Language, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return slices.Clone(stubLanguages), nil
+}
+
+// TranslateContext returns the text prefixed with the target language code,
+// such as "[es] Hello".
+func (s *StubClient) TranslateContext(ctx context.Context, query, source, target string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	return "[" + target + "] " + query, nil
+}
+
+// Stream translates the messages like TranslateContext, coalescing them in
+// batches like Client.Stream.
+func (s *StubClient) Stream(ctx context.Context, messages <-chan string, source, target string, window time.Duration) <-chan StreamResult {
+	return streamBatches(ctx, messages, window, func(ctx context.Context, batch []string) ([]string, error) {
+		translated := make([]string, len(batch))
+		for i, query := range batch {
+			text, err := s.TranslateContext(ctx, query, source, target)
+			if err != nil {
+				return nil, err
+			}
+
+			translated[i] = text
+		}
+
+		return translated, nil
+	})
+}
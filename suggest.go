@@ -0,0 +1,82 @@
+package libretranslate
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Suggestion is a corrected translation of a text.
+type Suggestion struct {
+	// Original text
+	Query string
+	// Language of the original text
+	Source string
+	// Language of the translation
+	Target string
+	// Suggested translation
+	Translated string
+}
+
+// Suggest makes a request to submit a better translation of a text. It returns
+// whether the server accepted the suggestion.
+func (c *Client) Suggest(suggestion Suggestion) (bool, error) {
+	return c.SuggestContext(context.Background(), suggestion)
+}
+
+// SuggestContext is like Suggest but uses the given context for the request.
+func (c *Client) SuggestContext(ctx context.Context, suggestion Suggestion) (bool, error) {
+	key, err := c.apiKey(ctx, "")
+	if err != nil {
+		return false, err
+	}
+
+	params := url.Values{}
+	params.Set("q", suggestion.Query)
+	params.Set("s", suggestion.Translated)
+	params.Set("source", suggestion.Source)
+	params.Set("target", suggestion.Target)
+	params.Set("api_key", key)
+
+	res, err := c.do(ctx, http.MethodPost, "/suggest", params)
+	if err != nil {
+		return false, err
+	}
+
+	defer res.Body.Close()
+
+	result := struct {
+		Success bool `json:"success"`
+	}{}
+	if err := c.decode(res, &result); err != nil {
+		return false, err
+	}
+
+	return result.Success, nil
+}
+
+// SuggestBatch submits several suggestions, one request at a time, and
+// reports whether each of them was accepted. Rate limited requests are
+// retried according to WithRetry.
+//
+// A failed suggestion does not stop the batch: its error is reported in a
+// *BatchError returned along with the results. When the context is canceled,
+// the remaining suggestions are not sent and fail with the context error.
+func (c *Client) SuggestBatch(ctx context.Context, suggestions []Suggestion) ([]bool, error) {
+	accepted := make([]bool, len(suggestions))
+	errs := make([]error, len(suggestions))
+
+	for i, suggestion := range suggestions {
+		if err := ctx.Err(); err != nil {
+			for j := i; j < len(suggestions); j++ {
+				errs[j] = err
+			}
+
+			break
+		}
+
+		accepted[i], errs[i] = c.SuggestContext(ctx, suggestion)
+	}
+
+	return accepted, batchError(errs)
+}
@@ -0,0 +1,53 @@
+package libretranslate
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+)
+
+// tagsContextKey is the context key of the tags set by ContextWithTags.
+type tagsContextKey struct{}
+
+// ContextWithTags returns a copy of the context carrying the given tags, such
+// as a user or request ID, added to the tags it already carries. The tags of
+// a call are added to the records the client logs for it (see WithLogger),
+// and middleware can read them from the context of the requests with
+// TagsFromContext, for instance to label metrics.
+func ContextWithTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := maps.Clone(TagsFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(tags))
+	}
+
+	maps.Copy(merged, tags)
+
+	return context.WithValue(ctx, tagsContextKey{}, merged)
+}
+
+// TagsFromContext returns the tags set with ContextWithTags, or nil if there
+// are none. The returned map must not be modified.
+func TagsFromContext(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(tagsContextKey{}).(map[string]string)
+
+	return tags
+}
+
+// log logs a record with the logger of the client, if any, adding the tags of
+// the context in a "tags" group.
+func (c *Client) log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if c.logger == nil || !c.logger.Enabled(ctx, level) {
+		return
+	}
+
+	if tags := TagsFromContext(ctx); len(tags) > 0 {
+		attrs := make([]any, 0, len(tags))
+		for _, key := range sortedKeys(tags) {
+			attrs = append(attrs, slog.String(key, tags[key]))
+		}
+
+		args = append(args, slog.Group("tags", attrs...))
+	}
+
+	c.logger.Log(ctx, level, msg, args...)
+}
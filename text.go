@@ -0,0 +1,158 @@
+package libretranslate
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Splitter splits a text into the segments processed separately by the
+// helpers working on long texts.
+type Splitter func(text string) []string
+
+// paragraphBreak matches the blank lines separating paragraphs.
+var paragraphBreak = regexp.MustCompile(`\n\s*\n`)
+
+// SplitParagraphs splits a text on blank lines, dropping empty paragraphs.
+func SplitParagraphs(text string) []string {
+	var paragraphs []string
+
+	for _, paragraph := range paragraphBreak.Split(text, -1) {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			paragraphs = append(paragraphs, paragraph)
+		}
+	}
+
+	return paragraphs
+}
+
+// SampleEvenly returns a Splitter keeping at most n segments of the given
+// splitter, evenly spaced over the text.
+func SampleEvenly(split Splitter, n int) Splitter {
+	return func(text string) []string {
+		segments := split(text)
+		if n <= 0 || len(segments) <= n {
+			return segments
+		}
+
+		samples := make([]string, n)
+		for i := range samples {
+			samples[i] = segments[i*len(segments)/n]
+		}
+
+		return samples
+	}
+}
+
+// SplitSentences splits a text after sentence-ending punctuation (followed by
+// whitespace, except for CJK punctuation) and on line breaks, dropping empty
+// sentences.
+func SplitSentences(text string) []string {
+	var sentences []string
+
+	for _, sentence := range splitSentencesKeepSpace(text) {
+		if sentence = strings.TrimSpace(sentence); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+	}
+
+	return sentences
+}
+
+// splitSentencesKeepSpace is like SplitSentences but keeps the whitespace
+// between the sentences, so joining them gives back the text.
+func splitSentencesKeepSpace(text string) []string {
+	var (
+		sentences []string
+		start     int
+	)
+
+	runes := []rune(text)
+	offset := 0
+
+	for i, r := range runes {
+		offset += utf8.RuneLen(r)
+
+		if r == '\n' ||
+			strings.ContainsRune("。！？", r) ||
+			strings.ContainsRune(".!?", r) && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])) {
+			sentences = append(sentences, text[start:offset])
+			start = offset
+		}
+	}
+
+	if start < len(text) {
+		sentences = append(sentences, text[start:])
+	}
+
+	return sentences
+}
+
+// surroundingSpace returns the leading and trailing whitespace of a text. A
+// text made only of whitespace is all leading whitespace.
+func surroundingSpace(text string) (lead, trail string) {
+	core := strings.TrimSpace(text)
+	if core == "" {
+		return text, ""
+	}
+
+	start := strings.Index(text, core)
+
+	return text[:start], text[start+len(core):]
+}
+
+// CountCharacters returns the number of characters of the given texts, as
+// counted by the server against its character limit.
+func CountCharacters(queries []string) int {
+	count := 0
+	for _, query := range queries {
+		count += utf8.RuneCountInString(query)
+	}
+
+	return count
+}
+
+// EstimateCost returns the estimated cost of translating the given texts on
+// an instance charging pricePerMillionChars per million characters, without
+// making any request. The characters are counted like CountCharacters; the
+// overhead of the requests (parameters, batching) is not charged by metered
+// instances and is ignored.
+//
+// The estimate is for a single pass: features sending texts several times,
+// such as RoundTrip or WithChunkOverlap, cost more.
+func EstimateCost(queries []string, pricePerMillionChars float64) float64 {
+	return float64(CountCharacters(queries)) * pricePerMillionChars / 1e6
+}
+
+// ChunkBatch splits a batch of texts into consecutive chunks of at most
+// charLimit characters each, which can be sent as separate batch requests.
+// A text longer than the limit is put alone in its chunk. A limit lower than
+// 1 (such as the -1 reported by instances without limit) gives a single chunk.
+func ChunkBatch(queries []string, charLimit int) [][]string {
+	if len(queries) == 0 {
+		return nil
+	}
+
+	if charLimit < 1 {
+		return [][]string{queries}
+	}
+
+	var (
+		chunks [][]string
+		start  int
+		size   int
+	)
+
+	for i, query := range queries {
+		count := utf8.RuneCountInString(query)
+		if i > start && size+count > charLimit {
+			chunks = append(chunks, queries[start:i:i])
+			start, size = i, 0
+		}
+
+		size += count
+	}
+
+	return append(chunks, queries[start:])
+}
@@ -0,0 +1,48 @@
+package libretranslate
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// WithEndpointTimeout sets a timeout for the calls to the given endpoint (for
+// instance "/translate" or "/languages"), covering all the attempts of a
+// request and the reading of its response.
+//
+// The timeout is applied to the context of each call, so the deadline of a
+// caller-supplied context still applies if it is earlier. Endpoints without
+// a timeout only depend on the caller context and the http.Client.
+func WithEndpointTimeout(endpoint string, timeout time.Duration) Option {
+	return func(c *Client) {
+		if c.endpointTimeouts == nil {
+			c.endpointTimeouts = make(map[string]time.Duration)
+		}
+
+		c.endpointTimeouts[endpoint] = timeout
+	}
+}
+
+// endpointContext returns a context with the timeout of the given endpoint,
+// and its cancel function, or a nil cancel function if there is no timeout.
+func (c *Client) endpointContext(ctx context.Context, endpoint string) (context.Context, context.CancelFunc) {
+	timeout, ok := c.endpointTimeouts[endpoint]
+	if !ok || timeout <= 0 {
+		return ctx, nil
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// cancelOnClose is a response body canceling the context of its request when
+// closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,131 @@
+package libretranslate
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// WithHTTPClient sets the http.Client used to send the requests. The default
+// is http.DefaultClient.
+//
+// The options configuring the http.Client apply to a copy of it: WithTimeout
+// replaces its Timeout unless WithKeepHTTPClientTimeout is set, and
+// WithMinTLSVersion does not apply to it.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = client
+	}
+}
+
+// WithIdleConnTimeout closes the connections kept idle for longer than the
+// given duration, instead of the 90 seconds of the default transport.
+//
+// Load balancers close idle connections after a while (60 seconds by default
+// for AWS application load balancers), and reusing a connection they just
+// closed fails with a "connection reset by peer" error. Setting a timeout a
+// bit lower than the one of the load balancer, such as 50 seconds, avoids
+// it; WithRetry also retries the requests that still fail with a connection error.
+//
+// The transport of the http.Client (or the default transport) is cloned, so
+// the http.Client given to WithHTTPClient is not modified. Transports other
+// than *http.Transport are left as they are.
+func WithIdleConnTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.idleConnTimeout = timeout
+	}
+}
+
+// WithMinTLSVersion sets the minimum TLS version of the connections, such as
+// tls.VersionTLS12, by cloning the default transport.
+//
+// It does not apply to an http.Client given to WithHTTPClient, whose TLS
+// configuration wins.
+func WithMinTLSVersion(version uint16) Option {
+	return func(c *Client) {
+		c.minTLSVersion = version
+	}
+}
+
+// WithTimeout sets the time limit of each request made by the http.Client,
+// including the reading of the response, as http.Client.Timeout does. A
+// retried request gets a new time limit for each attempt.
+//
+// It also applies to an http.Client given to WithHTTPClient, replacing its
+// Timeout in a copy (the given http.Client is not modified), unless
+// WithKeepHTTPClientTimeout is set. A zero timeout leaves the Timeout of the
+// http.Client as it is.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithKeepHTTPClientTimeout makes WithTimeout leave the Timeout of the
+// http.Client given to WithHTTPClient as it is. It has no effect on the
+// default http.Client.
+func WithKeepHTTPClientTimeout() Option {
+	return func(c *Client) {
+		c.keepClientTimeout = true
+	}
+}
+
+// configureTransport returns a copy of the http.Client of the client applying
+// the timeout and the transport options, or the http.Client itself if there
+// are none.
+func (c *Client) configureTransport() *http.Client {
+	base, custom := c.httpClient, c.httpClient != nil
+	if !custom {
+		base = http.DefaultClient
+	}
+
+	minTLSVersion := c.minTLSVersion
+	if custom {
+		minTLSVersion = 0
+	}
+
+	timeout := c.timeout
+	if custom && c.keepClientTimeout {
+		timeout = 0
+	}
+
+	if c.idleConnTimeout <= 0 && minTLSVersion == 0 && timeout <= 0 {
+		return base
+	}
+
+	client := *base
+	if timeout > 0 {
+		client.Timeout = timeout
+	}
+
+	if c.idleConnTimeout <= 0 && minTLSVersion == 0 {
+		return &client
+	}
+
+	transport, ok := client.Transport.(*http.Transport)
+	if client.Transport == nil {
+		transport, ok = http.DefaultTransport.(*http.Transport)
+	}
+
+	if !ok {
+		return &client
+	}
+
+	transport = transport.Clone()
+
+	if c.idleConnTimeout > 0 {
+		transport.IdleConnTimeout = c.idleConnTimeout
+	}
+
+	if minTLSVersion != 0 {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+
+		transport.TLSClientConfig.MinVersion = minTLSVersion
+	}
+
+	client.Transport = transport
+
+	return &client
+}
@@ -0,0 +1,92 @@
+package libretranslate
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestConfigureTransportTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   *http.Client
+		opts     []Option
+		want     time.Duration
+		sameUser bool
+	}{
+		{"default client", nil, nil, 0, false},
+		{"default client with timeout", nil, []Option{WithTimeout(5 * time.Second)}, 5 * time.Second, false},
+		{"default client keeping its timeout", nil, []Option{WithTimeout(5 * time.Second), WithKeepHTTPClientTimeout()}, 5 * time.Second, false},
+		{"user client", &http.Client{Timeout: time.Minute}, nil, time.Minute, true},
+		{"user client with timeout", &http.Client{Timeout: time.Minute}, []Option{WithTimeout(5 * time.Second)}, 5 * time.Second, false},
+		{"user client keeping its timeout", &http.Client{Timeout: time.Minute}, []Option{WithTimeout(5 * time.Second), WithKeepHTTPClientTimeout()}, time.Minute, true},
+		{"user client with zero timeout", &http.Client{Timeout: time.Minute}, []Option{WithTimeout(0)}, time.Minute, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			if tt.client != nil {
+				opts = append([]Option{WithHTTPClient(tt.client)}, opts...)
+			}
+
+			c := NewClientWithBaseURL("http://localhost:5000", "key", opts...)
+
+			if c.client.Timeout != tt.want {
+				t.Errorf("got timeout %s, want %s", c.client.Timeout, tt.want)
+			}
+
+			if tt.client != nil {
+				if tt.client.Timeout != time.Minute {
+					t.Errorf("the given http.Client was modified: got timeout %s, want %s", tt.client.Timeout, time.Minute)
+				}
+
+				if same := c.client == tt.client; same != tt.sameUser {
+					t.Errorf("got the given http.Client used as is %v, want %v", same, tt.sameUser)
+				}
+			}
+
+			if tt.client == nil && tt.want == 0 && c.client != http.DefaultClient {
+				t.Error("got a copy of http.DefaultClient, want http.DefaultClient itself")
+			}
+
+			if http.DefaultClient.Timeout != 0 {
+				t.Errorf("http.DefaultClient was modified: got timeout %s", http.DefaultClient.Timeout)
+			}
+		})
+	}
+}
+
+func TestConfigureTransportDoesNotModifyUserTransport(t *testing.T) {
+	transport := &http.Transport{IdleConnTimeout: time.Minute}
+	client := &http.Client{Transport: transport, Timeout: time.Minute}
+
+	c := NewClientWithBaseURL("http://localhost:5000", "key",
+		WithHTTPClient(client),
+		WithIdleConnTimeout(50*time.Second),
+		WithMinTLSVersion(tls.VersionTLS12),
+		WithTimeout(5*time.Second),
+	)
+
+	if c.client == client {
+		t.Fatal("got the given http.Client used as is, want a copy")
+	}
+
+	got, ok := c.client.Transport.(*http.Transport)
+	if !ok || got == transport {
+		t.Fatalf("got transport %T (%p), want a clone of %p", c.client.Transport, got, transport)
+	}
+
+	if got.IdleConnTimeout != 50*time.Second {
+		t.Errorf("got idle timeout %s, want %s", got.IdleConnTimeout, 50*time.Second)
+	}
+
+	if got.TLSClientConfig != nil && got.TLSClientConfig.MinVersion != 0 {
+		t.Errorf("got minimum TLS version %#x on a given http.Client, want none", got.TLSClientConfig.MinVersion)
+	}
+
+	if transport.IdleConnTimeout != time.Minute || client.Transport != transport || client.Timeout != time.Minute {
+		t.Error("the given http.Client or its transport was modified")
+	}
+}
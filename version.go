@@ -0,0 +1,142 @@
+package libretranslate
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// versionRetryDelay is the time WithVersionDetection waits after a failed
+// fetch of the server version before trying again.
+const versionRetryDelay = time.Minute
+
+// versionedParams gives the first server version accepting each optional
+// parameter. Older servers do not receive these parameters.
+var versionedParams = map[string]serverVersion{
+	"alternatives": {1, 5, 0},
+}
+
+// serverVersion holds the major, minor and patch numbers of a version.
+type serverVersion [3]int
+
+// parseVersion parses a version such as "1.6.2" or "v1.6", ignoring any
+// suffix after the numbers. Missing numbers are zero.
+func parseVersion(s string) (serverVersion, bool) {
+	var v serverVersion
+
+	parts := strings.SplitN(strings.TrimPrefix(strings.TrimSpace(s), "v"), ".", 3)
+	for i, part := range parts {
+		if end := strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+			part = part[:end]
+		}
+
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return v, i > 0
+		}
+
+		v[i] = n
+	}
+
+	return v, true
+}
+
+// less reports whether v is older than other.
+func (v serverVersion) less(other serverVersion) bool {
+	for i := range v {
+		if v[i] != other[i] {
+			return v[i] < other[i]
+		}
+	}
+
+	return false
+}
+
+// WithVersionDetection makes the client fetch the version of the server
+// before its first translation, as GetServerVersion does, and stop sending
+// the parameters the server does not support yet, such as "alternatives"
+// before version 1.5. If the version cannot be fetched or parsed, the
+// parameters are sent as usual, and a failed fetch is only tried again after
+// a minute, so an instance without a specification does not cost a request
+// per translation.
+//
+// Without this option, the parameters are only adapted once the version is
+// known from a call to GetServerVersion.
+func WithVersionDetection() Option {
+	return func(c *Client) {
+		c.versionDetection = true
+	}
+}
+
+// GetServerVersion returns the version of the server, such as "1.6.2", as
+// reported by its API specification. The version is fetched on the first call
+// and then read from the client cache.
+//
+// Only the version is decoded from the specification, so WithStrictDecoding
+// does not apply to it.
+func (c *Client) GetServerVersion(ctx context.Context) (string, error) {
+	if version, ok := c.cache.getVersion(); ok {
+		return version, nil
+	}
+
+	key, err := c.apiKey(ctx, "")
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("api_key", key)
+
+	res, err := c.do(ctx, http.MethodGet, "/spec", params)
+	if err != nil {
+		return "", err
+	}
+
+	defer res.Body.Close()
+
+	spec := struct {
+		Info struct {
+			Version string `json:"version"`
+		} `json:"info"`
+	}{}
+
+	// The specification holds much more than the version, so it is never
+	// decoded strictly.
+	body := &countingReader{r: res.Body}
+	if err := json.NewDecoder(body).Decode(&spec); err != nil {
+		return "", decodeError(res, body, err)
+	}
+
+	c.cache.setVersion(spec.Info.Version)
+
+	return spec.Info.Version, nil
+}
+
+// adaptParams removes the parameters the server does not support, when its
+// version is known or detected with WithVersionDetection.
+func (c *Client) adaptParams(ctx context.Context, params url.Values) {
+	version, ok := c.cache.getVersion()
+	if !ok && c.versionDetection && !c.cache.versionFailedSince(time.Now().Add(-versionRetryDelay)) {
+		var err error
+		if version, err = c.GetServerVersion(ctx); err != nil && ctx.Err() == nil {
+			c.cache.setVersionFailure(time.Now())
+			c.log(ctx, slog.LevelWarn, "libretranslate: version detection failed", "error", err)
+		}
+	}
+
+	v, ok := parseVersion(version)
+	if !ok {
+		return
+	}
+
+	for param, since := range versionedParams {
+		if v.less(since) {
+			params.Del(param)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package libretranslate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGetServerVersionStrictDecoding(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"swagger":"2.0","info":{"title":"LibreTranslate","version":"1.6.2"},"paths":{}}`))
+	}))
+	defer srv.Close()
+
+	c := NewClientWithBaseURL(srv.URL, "key", WithStrictDecoding())
+
+	version, err := c.GetServerVersion(context.Background())
+	if err != nil {
+		t.Fatalf("GetServerVersion: %v", err)
+	}
+
+	if version != "1.6.2" {
+		t.Errorf("got version %q, want %q", version, "1.6.2")
+	}
+}
+
+func TestVersionDetectionFailureIsNotRetriedPerCall(t *testing.T) {
+	var specCalls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/spec" {
+			specCalls.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not Found"}`))
+
+			return
+		}
+
+		w.Write([]byte(`{"translatedText":"hola"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClientWithBaseURL(srv.URL, "key", WithVersionDetection())
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.Translate("hello", "en", "es"); err != nil {
+			t.Fatalf("Translate: %v", err)
+		}
+	}
+
+	if got := specCalls.Load(); got != 1 {
+		t.Errorf("got %d requests to /spec, want 1", got)
+	}
+}